@@ -0,0 +1,354 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+)
+
+// App is a CLI program that owns its registered commands, its arguments, its
+// outputs and its exit behavior.
+type App struct {
+	// The program name displayed in usages. Default is the base name of the
+	// program path.
+	Name string
+
+	// The program arguments, without the program name.
+	Args []string
+
+	// The writer where built-in commands, such as completion, write their
+	// output. Default is os.Stdout.
+	Stdout io.Writer
+
+	// The writer where usages and errors are written. Default is os.Stderr.
+	Stderr io.Writer
+
+	// The reader where command input and prompted option values are read.
+	// Options are only prompted when it is a terminal. Default is os.Stdin.
+	Stdin io.Reader
+
+	// The function to look up environment variables. Default is os.LookupEnv.
+	LookupEnv func(key string) (string, bool)
+
+	// The dotenv files where environment variables that are not set are looked
+	// up. Variables of a file override the ones of the previous files. Missing
+	// files are ignored.
+	EnvFiles []string
+
+	// The sources where option values are looked up, from the lowest to the
+	// highest precedence. Values from config files have a lower precedence and
+	// values from flags and arguments a higher one. Default is the dotenv
+	// files followed by the environment.
+	Sources []Source
+
+	// The function called to exit the program. Default is os.Exit. When it
+	// returns, Run returns without executing the command and Load returns
+	// NoCommand.
+	Exit func(code int)
+
+	manager       commandManager
+	middlewares   []Middleware
+	currentUsage  func()
+	loadedCommand string
+}
+
+// New creates an app with the given program name that uses the arguments,
+// outputs and environment of the current process.
+func New(name string) *App {
+	return &App{
+		Name:      name,
+		Args:      os.Args[1:],
+		Stdout:    os.Stdout,
+		Stderr:    os.Stderr,
+		Stdin:     os.Stdin,
+		LookupEnv: os.LookupEnv,
+		Exit:      os.Exit,
+	}
+}
+
+// Register registers and returns the named command.
+func (a *App) Register(cmd ...string) Command {
+	return a.commandManager().register(cmd...)
+}
+
+// NoCommand is the command name returned by Load when the program exit is
+// requested and the app Exit function returns, such as after an error or after
+// a built-in command is executed. Unlike the root command name, which is empty,
+// it cannot be matched by the program arguments.
+const NoCommand = "\x00"
+
+// Load loads the registered command that matches the app args. If defined,
+// environment variables and flags are loaded in the command options.
+//
+// It prints the command usage and exits the program with code -1 when an error
+// occurs. Built-in commands, such as the ones registered by RegisterCompletion,
+// are executed and exit the program with code 0, as well as the --print-config
+// flag that prints the loaded options and the --version flag registered by
+// RegisterVersion. NoCommand is returned in those cases when the Exit function
+// returns.
+func (a *App) Load() (cmd string) {
+	c, err := a.load()
+	if err != nil {
+		a.exit(-1)
+		return NoCommand
+	}
+
+	if a.printVersion() || a.printConfig() {
+		return NoCommand
+	}
+
+	if c.builtin != nil {
+		if err := c.builtin(); err != nil {
+			a.Error(err)
+			return NoCommand
+		}
+		a.exit(0)
+		return NoCommand
+	}
+
+	return c.name
+}
+
+// Run loads the registered command that matches the app args and executes its
+// handler with the given context. The handler is wrapped by the app
+// middlewares and by the Before and After hooks of the command and of its
+// parents. Built-in commands are executed without middlewares and hooks.
+//
+// It prints the command usage and exits the program with code -1 when the
+// command cannot be loaded or does not have a handler. When the handler
+// returns an error, the error is printed and the program exits with the code
+// returned by ExitCode.
+func (a *App) Run(ctx context.Context) {
+	c, err := a.load()
+	if err != nil {
+		a.exit(-1)
+		return
+	}
+
+	if a.printVersion() || a.printConfig() {
+		return
+	}
+
+	var handler Handler
+	switch {
+	case c.builtin != nil:
+		handler = func(context.Context) error {
+			return c.builtin()
+		}
+
+	case c.handler != nil:
+		handler = a.wrap(commandLineage(a.manager.commands, c.name), c.handler)
+
+	default:
+		a.currentUsage()
+		a.exit(-1)
+		return
+	}
+
+	if err := handler(ctx); err != nil {
+		a.printError(err)
+		a.exit(ExitCode(err))
+	}
+}
+
+// Usage prints the loaded command usage. It panics when called before the Load
+// function.
+func (a *App) Usage() {
+	if a.currentUsage == nil {
+		panic("usage func is called before load func")
+	}
+	a.currentUsage()
+}
+
+// Error prints the given error and exit the program with code -1.
+func (a *App) Error(err error) {
+	a.printError(err)
+	a.exit(-1)
+}
+
+func (a *App) load() (*command, error) {
+	m := a.commandManager()
+
+	cmd, usage, err := m.parse(a.Args...)
+	a.currentUsage = usage
+	a.loadedCommand = ""
+
+	if err != nil {
+		if !errors.Is(err, errNoRootCmd) && !errors.Is(err, flag.ErrHelp) {
+			a.printError(err)
+		}
+
+		if usage != nil {
+			usage()
+		}
+		return nil, err
+	}
+
+	// Programs without root command can print their version.
+	if _, ok := m.commands[cmd]; !ok && m.printVersion != "" {
+		return &command{}, nil
+	}
+
+	c := m.commands[cmd].(*command)
+	a.loadedCommand = c.name
+
+	if c.deprecated != "" {
+		w, _ := usageOutput(a.stderr(), a.LookupEnv)
+		printWarning(w, fmt.Sprintf("command %q is deprecated: %s", commandString(m.programName(), c.name), c.deprecated))
+	}
+	return c, nil
+}
+
+// printConfig prints the loaded options and exits the program with code 0
+// when the --print-config flag is set. It reports whether the options were
+// printed.
+func (a *App) printConfig() bool {
+	format := a.manager.printConfig
+	if format == "" {
+		return false
+	}
+
+	if err := a.Dump(a.stdout(), format); err != nil {
+		a.Error(err)
+		return true
+	}
+
+	a.exit(0)
+	return true
+}
+
+// printVersion prints the program build information and exits the program
+// with code 0 when the --version flag is set. It reports whether the version
+// was printed.
+func (a *App) printVersion() bool {
+	format := a.manager.printVersion
+	if format == "" {
+		return false
+	}
+
+	if err := WriteVersion(a.stdout(), format); err != nil {
+		a.Error(err)
+		return true
+	}
+
+	a.exit(0)
+	return true
+}
+
+// commandManager returns the app command manager, set up with the current app
+// settings.
+func (a *App) commandManager() *commandManager {
+	a.manager.name = a.Name
+	a.manager.out = a.stderr()
+	a.manager.stdout = a.stdout()
+	a.manager.stdin = a.stdin()
+	a.manager.lookupEnv = a.LookupEnv
+	a.manager.sources = a.sources()
+	return &a.manager
+}
+
+// printError prints the given error on the app error output.
+func (a *App) printError(err error) {
+	w, _ := usageOutput(a.stderr(), a.LookupEnv)
+	printError(w, err)
+}
+
+// sources returns the chain of sources where option values are looked up.
+func (a *App) sources() []Source {
+	if a.Sources != nil {
+		return a.Sources
+	}
+
+	lookupEnv := a.LookupEnv
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+
+	var sources []Source
+	if len(a.EnvFiles) != 0 {
+		sources = append(sources, newDotenvSource(lookupEnv, a.EnvFiles))
+	}
+	return append(sources, EnvSource(lookupEnv))
+}
+
+// LoadedCommand returns the name of the command loaded by Load or Run. It
+// returns an empty string when the root command is loaded or when no command
+// is loaded.
+func (a *App) LoadedCommand() string {
+	return a.loadedCommand
+}
+
+// LoadedOption is an option of the loaded command.
+type LoadedOption struct {
+	// The option name.
+	Name string
+
+	// The option environment variable key. It is empty when the option is not
+	// loaded from the environment.
+	Env string
+
+	// The option value, with the type of the option field. It is nil when the
+	// option belongs to an optional struct that is not configured. Secret
+	// values are not masked.
+	Value interface{}
+
+	// The option value source. eg. "default", "env:PORT" or "flag:--port".
+	Source string
+}
+
+// LoadedOptions returns the options of the command loaded by Load or Run, with
+// their values and sources. Nested structs are described by their options, and
+// the --config flag added by the package is not returned.
+func (a *App) LoadedOptions() []LoadedOption {
+	opts := make([]LoadedOption, 0, len(a.manager.options))
+	for _, o := range a.manager.options {
+		if o.isInternal || (o.value.Kind() == reflect.Struct && !o.isText()) {
+			continue
+		}
+
+		lo := LoadedOption{
+			Name:   o.name,
+			Source: o.sourceString(),
+		}
+		if o.envKey != "-" {
+			lo.Env = o.envKey
+		}
+		if o.isConfigured() {
+			lo.Value = o.value.Interface()
+		}
+		opts = append(opts, lo)
+	}
+	return opts
+}
+
+func (a *App) stdout() io.Writer {
+	if a.Stdout == nil {
+		return os.Stdout
+	}
+	return a.Stdout
+}
+
+func (a *App) stdin() io.Reader {
+	if a.Stdin == nil {
+		return os.Stdin
+	}
+	return a.Stdin
+}
+
+func (a *App) stderr() io.Writer {
+	if a.Stderr == nil {
+		return os.Stderr
+	}
+	return a.Stderr
+}
+
+func (a *App) exit(code int) {
+	if a.Exit == nil {
+		os.Exit(code)
+	}
+	a.Exit(code)
+}
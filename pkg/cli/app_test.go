@@ -0,0 +1,179 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppsAreIndependent(t *testing.T) {
+	newApp := func(args []string, env map[string]string) (*App, *bytes.Buffer) {
+		w := bytes.NewBuffer(nil)
+		app := New("test")
+		app.Args = args
+		app.Stderr = w
+		app.LookupEnv = func(k string) (string, bool) {
+			v, ok := env[k]
+			return v, ok
+		}
+		app.Exit = func(code int) {
+			t.Fatalf("unexpected exit with code %v", code)
+		}
+		return app, w
+	}
+
+	var fooOpts struct {
+		Int int
+	}
+	foo, _ := newApp([]string{"foo"}, map[string]string{"INT": "21"})
+	foo.Register("foo").Options(&fooOpts)
+
+	var barOpts struct {
+		Int int
+	}
+	bar, _ := newApp([]string{"bar", "-int", "42"}, nil)
+	bar.Register("bar").Options(&barOpts)
+
+	require.Equal(t, "foo", foo.Load())
+	require.Equal(t, 21, fooOpts.Int)
+
+	require.Equal(t, "bar", bar.Load())
+	require.Equal(t, 42, barOpts.Int)
+}
+
+func TestAppExit(t *testing.T) {
+	tests := []struct {
+		scenario string
+		args     []string
+		handler  func(context.Context) error
+		expected int
+		exited   bool
+	}{
+		{
+			scenario: "success does not exit",
+			handler:  func(context.Context) error { return nil },
+		},
+		{
+			scenario: "handler error exits with its code",
+			handler: func(context.Context) error {
+				return WithExitCode(errors.New("handler error"), 3)
+			},
+			expected: 3,
+			exited:   true,
+		},
+		{
+			scenario: "unknown command exits with -1",
+			args:     []string{"foo"},
+			handler:  func(context.Context) error { return nil },
+			expected: -1,
+			exited:   true,
+		},
+		{
+			scenario: "missing handler exits with -1",
+			expected: -1,
+			exited:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			w := bytes.NewBuffer(nil)
+
+			var code int
+			var exited bool
+
+			app := New("test")
+			app.Args = test.args
+			app.Stderr = w
+			app.Exit = func(c int) {
+				code = c
+				exited = true
+			}
+
+			cmd := app.Register()
+			if test.handler != nil {
+				cmd.Run(test.handler)
+			}
+
+			app.Run(context.TODO())
+			require.Equal(t, test.exited, exited)
+			require.Equal(t, test.expected, code)
+			t.Log(w.String())
+		})
+	}
+}
+
+func TestAppLoadExitReturns(t *testing.T) {
+	code := 0
+
+	app := New("test")
+	app.Args = []string{"--unknown"}
+	app.Stderr = writerNoop{}
+	app.Exit = func(c int) { code = c }
+	app.Register().Help("A root command")
+
+	require.Equal(t, NoCommand, app.Load())
+	require.Equal(t, -1, code)
+}
+
+func TestAppUsage(t *testing.T) {
+	w := bytes.NewBuffer(nil)
+
+	app := New("myprogram")
+	app.Args = nil
+	app.Stderr = w
+	app.Register().Help("A test command")
+
+	require.Empty(t, app.Load())
+
+	app.Usage()
+	require.Contains(t, w.String(), "myprogram")
+	t.Log(w.String())
+}
+
+func TestAppDeprecatedCommand(t *testing.T) {
+	w := bytes.NewBuffer(nil)
+
+	app := New("test")
+	app.Args = []string{"migrate"}
+	app.Stderr = w
+	app.Register("migrate").Deprecated("use db migrate instead")
+
+	require.Equal(t, "migrate", app.Load())
+	require.Equal(t, "Warning:\n\n    command \"test migrate\" is deprecated: use db migrate instead\n\n", w.String())
+}
+
+func TestAppLoadedOptions(t *testing.T) {
+	type tlsOptions struct {
+		Cert string
+	}
+
+	var opts struct {
+		Port  int    `env:"-"`
+		Token string `cli:"token,secret"`
+		TLS   *tlsOptions
+	}
+
+	app := New("test")
+	app.Args = []string{"--port", "8080"}
+	app.Stderr = writerNoop{}
+	app.LookupEnv = func(k string) (string, bool) {
+		return "my-token", k == "TOKEN"
+	}
+	app.Exit = func(code int) {
+		t.Fatalf("unexpected exit with code %v", code)
+	}
+	app.Register().Options(&opts)
+
+	require.Empty(t, app.LoadedOptions())
+
+	app.Load()
+	require.Equal(t, []LoadedOption{
+		{Name: "port", Value: 8080, Source: "flag:--port"},
+		{Name: "token", Env: "TOKEN", Value: "my-token", Source: "env:TOKEN"},
+		{Name: "tls.cert", Env: "TLS_CERT", Source: "default"},
+	}, app.LoadedOptions())
+}
@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	restArgIndex = -1
+)
+
+// parseArg sets up the given option as a positional argument. The tag value
+// is either the argument index or "rest" for a slice that receives the
+// remaining arguments.
+func parseArg(o *option, tag string) error {
+	o.isArg = true
+	o.envKey = "-"
+	o.configPath = nil
+
+	if tag == "rest" {
+		if o.value.Kind() != reflect.Slice {
+			return errors.New(`"rest" argument is not a slice`)
+		}
+		o.argIndex = restArgIndex
+		return nil
+	}
+
+	idx, err := strconv.Atoi(tag)
+	if err != nil || idx < 0 {
+		return fmt.Errorf("invalid argument index: %q", tag)
+	}
+	o.argIndex = idx
+	return nil
+}
+
+// checkArgs ensures that argument indexes are contiguous and that there is at
+// most one rest argument.
+func checkArgs(opts []option) error {
+	args := commandArgs(opts)
+
+	for i, a := range args {
+		if a.argIndex == restArgIndex {
+			if i != len(args)-1 {
+				return errors.New(`multiple "rest" arguments`)
+			}
+			continue
+		}
+
+		if a.argIndex != i {
+			return fmt.Errorf("argument %q has index %v instead of %v", a.name, a.argIndex, i)
+		}
+	}
+
+	return nil
+}
+
+// commandArgs returns the positional arguments of the given options, sorted by
+// index with the rest argument last.
+func commandArgs(opts []option) []option {
+	var args []option
+	for _, o := range opts {
+		if o.isArg {
+			args = append(args, o)
+		}
+	}
+
+	sort.SliceStable(args, func(i, j int) bool {
+		a, b := args[i].argIndex, args[j].argIndex
+		if a == restArgIndex || b == restArgIndex {
+			return b == restArgIndex && a != restArgIndex
+		}
+		return a < b
+	})
+	return args
+}
+
+func hasArgs(opts []option) bool {
+	for _, o := range opts {
+		if o.isArg {
+			return true
+		}
+	}
+	return false
+}
+
+// setArgs sets the positional arguments of the given options with the given
+// values.
+func setArgs(opts []option, values []string) error {
+	args := commandArgs(opts)
+	var rest *option
+
+	for i, v := range values {
+		if i < len(args) && args[i].argIndex != restArgIndex {
+			if err := args[i].Set(v); err != nil {
+				return fmt.Errorf("invalid value %q for argument %s: %w", v, args[i].name, err)
+			}
+			args[i].setSource(sourceArg, "")
+			continue
+		}
+
+		if len(args) == 0 || args[len(args)-1].argIndex != restArgIndex {
+			return fmt.Errorf("unexpected argument: %q", v)
+		}
+
+		if rest == nil {
+			rest = &args[len(args)-1]
+			rest.value.Set(reflect.MakeSlice(rest.value.Type(), 0, len(values)-i))
+		}
+
+		if err := rest.append(v); err != nil {
+			return fmt.Errorf("invalid value %q for argument %s: %w", v, rest.name, err)
+		}
+		rest.setSource(sourceArg, "")
+	}
+
+	return nil
+}
+
+// argUsage returns the usage notation of the given positional argument.
+func argUsage(o option) string {
+	name := o.name
+	if o.argIndex == restArgIndex {
+		name += "..."
+	}
+
+	for _, r := range o.rules {
+		if r.name == "required" {
+			return "<" + name + ">"
+		}
+	}
+	return "[" + name + "]"
+}
+
+func argsUsage(opts []option) string {
+	args := commandArgs(opts)
+	usages := make([]string, 0, len(args))
+	for _, a := range args {
+		usages = append(usages, argUsage(a))
+	}
+	return strings.Join(usages, " ")
+}
@@ -0,0 +1,179 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandManagerParseArgs(t *testing.T) {
+	type migrateOptions struct {
+		Direction string `arg:"0" validate:"required,oneof=up|down"`
+		Steps     int    `arg:"1"`
+		DryRun    bool
+	}
+
+	type inspectOptions struct {
+		Verbose bool
+		Files   []string `arg:"rest"`
+	}
+
+	tests := []struct {
+		scenario string
+		args     []string
+		cmd      string
+		migrate  migrateOptions
+		inspect  inspectOptions
+		err      bool
+	}{
+		{
+			scenario: "fixed arguments",
+			args:     []string{"migrate", "up", "3"},
+			cmd:      "migrate",
+			migrate:  migrateOptions{Direction: "up", Steps: 3},
+		},
+		{
+			scenario: "fixed arguments with options",
+			args:     []string{"migrate", "down", "-dry-run", "2"},
+			cmd:      "migrate",
+			migrate:  migrateOptions{Direction: "down", Steps: 2, DryRun: true},
+		},
+		{
+			scenario: "optional argument is not set",
+			args:     []string{"migrate", "up"},
+			cmd:      "migrate",
+			migrate:  migrateOptions{Direction: "up", Steps: 1},
+		},
+		{
+			scenario: "missing required argument returns an error",
+			args:     []string{"migrate"},
+			err:      true,
+		},
+		{
+			scenario: "invalid argument value returns an error",
+			args:     []string{"migrate", "up", "three"},
+			err:      true,
+		},
+		{
+			scenario: "unexpected argument returns an error",
+			args:     []string{"migrate", "up", "3", "4"},
+			err:      true,
+		},
+		{
+			scenario: "rest arguments",
+			args:     []string{"inspect", "a.txt", "b.txt", "-verbose", "c.txt"},
+			cmd:      "inspect",
+			inspect:  inspectOptions{Verbose: true, Files: []string{"a.txt", "b.txt", "c.txt"}},
+		},
+		{
+			scenario: "rest arguments replace default values",
+			args:     []string{"inspect", "-verbose", "a.txt"},
+			cmd:      "inspect",
+			inspect:  inspectOptions{Verbose: true, Files: []string{"a.txt"}},
+		},
+		{
+			scenario: "arguments on command without arguments returns an error",
+			args:     []string{"status", "foo"},
+			err:      true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			migrate := migrateOptions{Steps: 1}
+			inspect := inspectOptions{Files: []string{"default.txt"}}
+
+			m := commandManager{out: &bytes.Buffer{}}
+			m.register("migrate").Options(&migrate)
+			m.register("inspect").Options(&inspect)
+			m.register("status")
+
+			cmd, _, err := m.parse(test.args...)
+			if test.err {
+				require.Error(t, err)
+				t.Log("error:", err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.cmd, cmd)
+
+			if cmd == "migrate" {
+				require.Equal(t, test.migrate, migrate)
+			} else {
+				require.Equal(t, test.inspect, inspect)
+			}
+		})
+	}
+}
+
+func TestCheckArgs(t *testing.T) {
+	tests := []struct {
+		scenario string
+		options  interface{}
+		err      bool
+	}{
+		{
+			scenario: "valid arguments",
+			options: &struct {
+				Rest   []string `arg:"rest"`
+				Second string   `arg:"1"`
+				First  string   `arg:"0"`
+			}{},
+		},
+		{
+			scenario: "invalid index returns an error",
+			options: &struct {
+				First string `arg:"first"`
+			}{},
+			err: true,
+		},
+		{
+			scenario: "missing index returns an error",
+			options: &struct {
+				First  string `arg:"0"`
+				Second string `arg:"2"`
+			}{},
+			err: true,
+		},
+		{
+			scenario: "non-slice rest argument returns an error",
+			options: &struct {
+				Rest string `arg:"rest"`
+			}{},
+			err: true,
+		},
+		{
+			scenario: "multiple rest arguments returns an error",
+			options: &struct {
+				Rest  []string `arg:"rest"`
+				Rest2 []string `arg:"rest"`
+			}{},
+			err: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			var p optionParser
+			err := p.collect(test.options)
+			if test.err {
+				require.Error(t, err)
+				t.Log("error:", err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestArgsUsage(t *testing.T) {
+	var p optionParser
+	err := p.collect(&struct {
+		Files     []string `arg:"rest"`
+		Direction string   `arg:"0" validate:"required"`
+		Steps     int      `arg:"1"`
+	}{})
+	require.NoError(t, err)
+	require.Equal(t, "<direction> [steps] [files...]", argsUsage(p.options))
+}
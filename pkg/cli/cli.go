@@ -2,59 +2,70 @@
 //
 // eg:
 //
-//	 func main() {
-//	     opts := struct {
-//		        Foo string `env:"FOO" help:"A simple string."`
-//			    Bar int    `env:"BAR" help:"A simple integer."`
-//	     }{
-//			    Foo: "foo",
-//			    Bar: 42,
-//	     }
+//	func main() {
+//		opts := struct {
+//			Foo string `env:"FOO" help:"A simple string."`
+//			Bar int    `env:"BAR" help:"A simple integer."`
+//		}{
+//			Foo: "foo",
+//			Bar: 42,
+//		}
 //
-//	     ctx, cancel := cli.ContextWithSignals(context.Background(),
-//	         os.Interrupt,
-//			    syscall.SIGTERM,
-//	     )
-//	     defer cancel()
+//		ctx, cancel := cli.ContextWithSignals(context.Background(),
+//			os.Interrupt,
+//			syscall.SIGTERM,
+//		)
+//		defer cancel()
 //
-//	     cli.Register().
-//			    Help("A simple command").
-//			    Options(&opts)
+//		cli.Register().
+//			Help("A simple command").
+//			Options(&opts).
+//			Run(func(ctx context.Context) error {
+//				return defaultCmd(ctx, opts)
+//			})
 //
-//	     cli.Register("hello").
-//			    Help("A sub command").
-//			    Options(&opts)
+//		cli.Register("hello").
+//			Help("A sub command").
+//			Options(&opts).
+//			Run(func(ctx context.Context) error {
+//				return helloCmd(ctx, opts)
+//			})
 //
-//	     cli.Register("world").
-//			    Help("Another sub command").
-//			    Options(&opts)
+//		cli.Register("world").
+//			Help("Another sub command").
+//			Options(&opts).
+//			Run(func(ctx context.Context) error {
+//				return worldCmd(ctx, opts)
+//			})
 //
-//	     switch cli.Load() {
-//		    case "hello":
-//			    helloCmd(ctx, opts)
+//		cli.Run(ctx)
+//	}
 //
-//	     case "world":
-//			    worldCmd(ctx, opts)
+// Commands can also be loaded without handlers, in which case Load returns the
+// name of the loaded command:
 //
-//		    default:
-//			    defaultCmd(ctx, opts)
-//		    }
-//	 }
+//	switch cli.Load() {
+//	case "hello":
+//		helloCmd(ctx, opts)
+//
+//	case "world":
+//		worldCmd(ctx, opts)
+//
+//	default:
+//		defaultCmd(ctx, opts)
+//	}
 package cli
 
 import (
 	"context"
 	"errors"
-	"flag"
+	"fmt"
 	"os"
 	"os/signal"
 )
 
 var (
-	defaultManager = commandManager{out: os.Stderr}
-	currentUsage   func()
-	exitOnError    = true
-	programArgs    = os.Args[1:]
+	defaultApp = New("")
 )
 
 // Command is the interface that describes a command.
@@ -65,55 +76,108 @@ type Command interface {
 	// Sets the command options with the given receiver. The receiver must be a
 	// pointer to a struct.
 	Options(interface{}) Command
+
+	// Sets the persistent options with the given receiver. Persistent options
+	// apply to the command and to all its subcommands. The receiver must be a
+	// pointer to a struct.
+	PersistentOptions(interface{}) Command
+
+	// Sets the handler that is executed by Run when the command is loaded.
+	Run(func(ctx context.Context) error) Command
+
+	// Sets the hook that is executed before the handler of the command and of
+	// its subcommands. When it returns an error, the handler is not executed.
+	Before(func(ctx context.Context) error) Command
+
+	// Sets the hook that is executed after the handler of the command and of
+	// its subcommands, when the Before hook of the command succeeded. It
+	// receives the handler error and returns the error reported by Run.
+	After(func(ctx context.Context, err error) error) Command
+
+	// Sets alternative names that can be used instead of the last word of the
+	// command name. Aliases of the root command are ignored.
+	Aliases(...string) Command
+
+	// Sets the group under which the command is listed in the command index.
+	Group(string) Command
+
+	// Hides the command from the command index, completion and documentation.
+	// The command can still be loaded.
+	Hidden() Command
+
+	// Marks the command as deprecated with the given message, eg. "use x
+	// instead". A warning is printed when the command is loaded.
+	Deprecated(string) Command
 }
 
-// Register registers and returns the named command.
+// Register registers and returns the named command in the default app.
 func Register(cmd ...string) Command {
-	return defaultManager.register(cmd...)
+	return defaultApp.Register(cmd...)
 }
 
-// Load loads the registered command that matches the program args. If defined,
-// environment variables and flags are loaded in the command options.
-//
-// It prints the command usage and exits the program with code -1 when an error
-// occurs.
+// Load loads the command of the default app that matches the program args. See
+// App.Load.
 func Load() (cmd string) {
-	cmd, usage, err := defaultManager.parse(programArgs...)
-	currentUsage = usage
-
-	if err != nil {
-		if errors.Is(err, errNoRootCmd) && err != flag.ErrHelp {
-			printError(defaultManager.out, err)
-		}
-
-		if usage != nil {
-			usage()
-		}
-
-		if exitOnError {
-			os.Exit(-1)
-		}
-		panic(err)
-	}
+	return defaultApp.Load()
+}
+
+// Run loads the command of the default app that matches the program args and
+// executes its handler. See App.Run.
+func Run(ctx context.Context) {
+	defaultApp.Run(ctx)
+}
 
-	return cmd
+// Use adds the given middlewares to the default app. See App.Use.
+func Use(mw ...Middleware) {
+	defaultApp.Use(mw...)
 }
 
-// Usage prints the loaded command usage. It panics when called before the Load
-// function.
+// Usage prints the loaded command usage of the default app. It panics when
+// called before the Load function.
 func Usage() {
-	if currentUsage == nil {
-		panic("usage func is called before load func")
+	defaultApp.Usage()
+}
+
+// WithExitCode returns an error that wraps the given error and makes Run exit
+// the program with the given code.
+func WithExitCode(err error, code int) error {
+	return exitError{err: err, code: code}
+}
+
+// ExitCode returns the program exit code that corresponds to the given error:
+// 0 when the error is nil, the code set with WithExitCode when the error chain
+// contains one, and 1 otherwise.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var exitErr exitError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
 	}
-	currentUsage()
+	return 1
+}
+
+type exitError struct {
+	err  error
+	code int
+}
+
+func (e exitError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("exit code %v", e.code)
+	}
+	return e.err.Error()
+}
+
+func (e exitError) Unwrap() error {
+	return e.err
 }
 
 // Error prints the given error and exit the program with code -1.
 func Error(err error) {
-	printError(defaultManager.out, err)
-	if exitOnError {
-		os.Exit(-1)
-	}
+	defaultApp.Error(err)
 }
 
 // ContextWithSignals returns a copy of the parent context that gets canceled
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"syscall"
 	"testing"
@@ -13,14 +14,16 @@ import (
 )
 
 func init() {
-	exitOnError = false
+	defaultApp.Exit = func(code int) {
+		panic(code)
+	}
 }
 
 func TestCliSuccess(t *testing.T) {
 	w := bytes.NewBufferString("\n")
-	defaultManager.out = w
-	defaultManager.commands = nil
-	programArgs = nil
+	defaultApp.Stderr = w
+	defaultApp.manager.commands = nil
+	defaultApp.Args = nil
 
 	Register().Help("A test command")
 	cmd := Load()
@@ -32,9 +35,9 @@ func TestCliSuccess(t *testing.T) {
 
 func TestCliIndex(t *testing.T) {
 	w := bytes.NewBufferString("\n")
-	defaultManager.out = w
-	defaultManager.commands = nil
-	programArgs = []string{"foo", "test"}
+	defaultApp.Stderr = w
+	defaultApp.manager.commands = nil
+	defaultApp.Args = []string{"foo", "test"}
 
 	Register("foo", "bar").Help("A test command")
 	Register("foo", "buu").Help("Another test command")
@@ -50,9 +53,9 @@ func TestCliIndex(t *testing.T) {
 
 func TestCliCmdBadOption(t *testing.T) {
 	w := bytes.NewBufferString("\n")
-	defaultManager.out = w
-	defaultManager.commands = nil
-	programArgs = []string{"-duration", "[x_x]"}
+	defaultApp.Stderr = w
+	defaultApp.manager.commands = nil
+	defaultApp.Args = []string{"-duration", "[x_x]"}
 
 	opts := struct {
 		Duration time.Duration
@@ -69,8 +72,94 @@ func TestCliCmdBadOption(t *testing.T) {
 	t.Fail()
 }
 
+func TestCliRun(t *testing.T) {
+	w := bytes.NewBufferString("\n")
+	defaultApp.Stderr = w
+	defaultApp.manager.commands = nil
+	defaultApp.Args = []string{"foo", "-int", "42"}
+
+	opts := struct {
+		Int int
+	}{}
+
+	var called bool
+	Register().Help("A root command")
+	Register("foo").
+		Help("A test command").
+		Options(&opts).
+		Run(func(ctx context.Context) error {
+			called = true
+			require.Equal(t, 42, opts.Int)
+			return nil
+		})
+
+	Run(context.TODO())
+	require.True(t, called)
+}
+
+func TestCliRunRoot(t *testing.T) {
+	w := bytes.NewBufferString("\n")
+	defaultApp.Stderr = w
+	defaultApp.manager.commands = nil
+	defaultApp.Args = nil
+
+	var called bool
+	Register().Run(func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+
+	Run(context.TODO())
+	require.True(t, called)
+}
+
+func TestCliRunError(t *testing.T) {
+	w := bytes.NewBufferString("\n")
+	defaultApp.Stderr = w
+	defaultApp.manager.commands = nil
+	defaultApp.Args = nil
+
+	Register().Run(func(ctx context.Context) error {
+		return WithExitCode(errors.New("handler error"), 3)
+	})
+
+	defer func() {
+		require.Equal(t, 3, recover())
+		require.Contains(t, w.String(), "handler error")
+		t.Log(w.String())
+	}()
+
+	Run(context.TODO())
+	t.Fail()
+}
+
+func TestCliRunWithoutHandler(t *testing.T) {
+	w := bytes.NewBufferString("\n")
+	defaultApp.Stderr = w
+	defaultApp.manager.commands = nil
+	defaultApp.Args = nil
+
+	Register().Help("A command without handler")
+
+	defer func() {
+		require.NotNil(t, recover())
+		t.Log(w.String())
+	}()
+
+	Run(context.TODO())
+	t.Fail()
+}
+
+func TestExitCode(t *testing.T) {
+	require.Zero(t, ExitCode(nil))
+	require.Equal(t, 1, ExitCode(errors.New("error")))
+	require.Equal(t, 42, ExitCode(WithExitCode(errors.New("error"), 42)))
+	require.Equal(t, 42, ExitCode(fmt.Errorf("wrapped: %w", WithExitCode(nil, 42))))
+	require.Equal(t, "exit code 42", WithExitCode(nil, 42).Error())
+}
+
 func TestUsagePanic(t *testing.T) {
-	currentUsage = nil
+	defaultApp.currentUsage = nil
 	require.Panics(t, func() {
 		Usage()
 	})
@@ -78,9 +167,16 @@ func TestUsagePanic(t *testing.T) {
 
 func TestError(t *testing.T) {
 	w := bytes.NewBufferString("\n")
-	defaultManager.out = w
+	defaultApp.Stderr = w
+
+	defer func() {
+		require.Equal(t, -1, recover())
+		require.Contains(t, w.String(), "error error critical error")
+		t.Log(w.String())
+	}()
+
 	Error(errors.New("error error critical error"))
-	t.Log(w.String())
+	t.Fail()
 }
 
 func TestContextWithSignals(t *testing.T) {
@@ -0,0 +1,162 @@
+// Package clitest provides utilities to test programs built with the cli
+// package.
+//
+// eg:
+//
+//	func TestHello(t *testing.T) {
+//		app := cli.New("myprogram")
+//		app.Register("hello").Options(&opts).Run(hello)
+//
+//		res := clitest.Run(context.Background(), app, clitest.Input{
+//			Args: []string{"hello", "--name", "world"},
+//			Env:  map[string]string{"LEVEL": "debug"},
+//		})
+//
+//		require.Equal(t, "hello", res.Command)
+//		require.Equal(t, 0, res.ExitCode)
+//		require.Equal(t, "hello world\n", res.Stdout)
+//	}
+package clitest
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/aukilabs/go-tooling/pkg/cli"
+)
+
+var (
+	update = flag.Bool("clitest.update", false, "Updates the golden files compared with clitest.Golden.")
+
+	ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+)
+
+// Input describes how a cli app is executed.
+type Input struct {
+	// The program arguments, without the program name.
+	Args []string
+
+	// The environment variables. They replace the app LookupEnv function,
+	// which is used by the default sources. Sources set in the app Sources
+	// field with their own lookup function, such as EnvSource(nil) or
+	// DotenvSource, still read the environment of the current process.
+	Env map[string]string
+
+	// The standard input. Since it is not a terminal, options are not
+	// prompted.
+	Stdin string
+}
+
+// Result describes the execution of a cli app.
+type Result struct {
+	// The name of the loaded command. It is empty when the root command is
+	// loaded or when no command is loaded.
+	Command string
+
+	// The code given to the app exit function. It is 0 when the app did not
+	// exit.
+	ExitCode int
+
+	// Whether the app exit function was called.
+	Exited bool
+
+	// The standard output, without colors.
+	Stdout string
+
+	// The usages and errors output, without colors.
+	Stderr string
+
+	// The loaded options, with their parsed value and their source. See
+	// cli.App.LoadedOptions.
+	Options []cli.LoadedOption
+}
+
+// Option returns the parsed value of the named option, or nil when the option
+// is not loaded.
+func (r Result) Option(name string) interface{} {
+	for _, o := range r.Options {
+		if o.Name == name {
+			return o.Value
+		}
+	}
+	return nil
+}
+
+// Load loads the given app with the given input. See cli.App.Load.
+func Load(app *cli.App, in Input) Result {
+	return execute(app, in, func() {
+		app.Load()
+	})
+}
+
+// Run loads the given app with the given input and executes the loaded
+// command handler. See cli.App.Run.
+func Run(ctx context.Context, app *cli.App, in Input) Result {
+	return execute(app, in, func() {
+		app.Run(ctx)
+	})
+}
+
+func execute(app *cli.App, in Input, run func()) Result {
+	var res Result
+	stdout := bytes.NewBuffer(nil)
+	stderr := bytes.NewBuffer(nil)
+
+	app.Args = in.Args
+	if app.Args == nil {
+		app.Args = []string{}
+	}
+	app.Stdout = stdout
+	app.Stderr = stderr
+	app.Stdin = strings.NewReader(in.Stdin)
+	app.LookupEnv = func(k string) (string, bool) {
+		v, ok := in.Env[k]
+		return v, ok
+	}
+	app.Exit = func(code int) {
+		if !res.Exited {
+			res.ExitCode = code
+			res.Exited = true
+		}
+	}
+
+	run()
+
+	res.Command = app.LoadedCommand()
+	res.Stdout = ansiEscape.ReplaceAllString(stdout.String(), "")
+	res.Stderr = ansiEscape.ReplaceAllString(stderr.String(), "")
+	res.Options = app.LoadedOptions()
+	return res
+}
+
+// Golden compares the given output with the content of the golden file at the
+// given path, usually in the testdata directory. The golden file is written
+// with the output when the test is run with the -clitest.update flag.
+func Golden(t testing.TB, filename, output string) {
+	t.Helper()
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			t.Fatalf("creating golden file directory failed: %s", err)
+		}
+		if err := os.WriteFile(filename, []byte(output), 0644); err != nil {
+			t.Fatalf("writing golden file failed: %s", err)
+		}
+		return
+	}
+
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("reading golden file failed: %s (run the test with -clitest.update to create it)", err)
+	}
+
+	if expected := string(b); output != expected {
+		t.Errorf("output does not match golden file %s:\n\nexpected:\n%s\nactual:\n%s", filename, expected, output)
+	}
+}
@@ -0,0 +1,150 @@
+package clitest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/aukilabs/go-tooling/pkg/cli"
+	"github.com/stretchr/testify/require"
+)
+
+type helloOptions struct {
+	Name  string `help:"The name to greet."`
+	Level string `env:"LEVEL"`
+}
+
+func newHelloApp() *cli.App {
+	app := cli.New("greeter")
+	app.Register("hello").
+		Help("Greets someone.").
+		Options(&helloOptions{Name: "world"}).
+		Run(func(ctx context.Context) error {
+			return nil
+		})
+	return app
+}
+
+func TestRun(t *testing.T) {
+	var opts helloOptions
+	var input string
+
+	app := cli.New("greeter")
+	app.Register("hello").
+		Options(&opts).
+		Run(func(ctx context.Context) error {
+			b, err := io.ReadAll(app.Stdin)
+			if err != nil {
+				return err
+			}
+			input = string(b)
+
+			fmt.Fprintf(app.Stdout, "\033[1mhello %s\033[0m\n", opts.Name)
+			return nil
+		})
+
+	res := Run(context.Background(), app, Input{
+		Args:  []string{"hello", "--name", "Maxence"},
+		Env:   map[string]string{"LEVEL": "debug"},
+		Stdin: "input",
+	})
+
+	require.Equal(t, "hello", res.Command)
+	require.False(t, res.Exited)
+	require.Equal(t, 0, res.ExitCode)
+	require.Equal(t, "hello Maxence\n", res.Stdout)
+	require.Empty(t, res.Stderr)
+	require.Equal(t, "input", input)
+	require.Equal(t, "Maxence", res.Option("name"))
+	require.Equal(t, "debug", res.Option("level"))
+	require.Nil(t, res.Option("missing"))
+	require.Equal(t, []cli.LoadedOption{
+		{Name: "name", Env: "NAME", Value: "Maxence", Source: "flag:--name"},
+		{Name: "level", Env: "LEVEL", Value: "debug", Source: "env:LEVEL"},
+	}, res.Options)
+}
+
+func TestLoadTypedOptions(t *testing.T) {
+	var opts struct {
+		Port    int
+		Timeout time.Duration
+		Token   string `cli:"token,secret"`
+	}
+
+	app := cli.New("server")
+	app.Register().Options(&opts)
+
+	res := Load(app, Input{
+		Args: []string{"--port", "8080", "--timeout", "5s"},
+		Env:  map[string]string{"TOKEN": "my-token"},
+	})
+
+	require.False(t, res.Exited)
+	require.Equal(t, 8080, res.Option("port"))
+	require.Equal(t, 5*time.Second, res.Option("timeout"))
+	require.Equal(t, "my-token", res.Option("token"))
+}
+
+func TestRunError(t *testing.T) {
+	app := cli.New("greeter")
+	app.Register("hello").Run(func(ctx context.Context) error {
+		return fmt.Errorf("greeting failed")
+	})
+
+	res := Run(context.Background(), app, Input{
+		Args: []string{"hello"},
+	})
+
+	require.Equal(t, "hello", res.Command)
+	require.True(t, res.Exited)
+	require.Equal(t, 1, res.ExitCode)
+	require.Contains(t, res.Stderr, "greeting failed")
+	require.NotContains(t, res.Stderr, "\033[")
+}
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		scenario string
+		args     []string
+		command  string
+		exited   bool
+		code     int
+	}{
+		{
+			scenario: "command is loaded",
+			args:     []string{"hello"},
+			command:  "hello",
+		},
+		{
+			scenario: "unknown command exits with -1",
+			args:     []string{"bye"},
+			exited:   true,
+			code:     -1,
+		},
+		{
+			scenario: "help exits with -1",
+			args:     []string{"hello", "--help"},
+			exited:   true,
+			code:     -1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			res := Load(newHelloApp(), Input{Args: test.args})
+			require.Equal(t, test.command, res.Command)
+			require.Equal(t, test.exited, res.Exited)
+			require.Equal(t, test.code, res.ExitCode)
+		})
+	}
+}
+
+func TestGolden(t *testing.T) {
+	res := Load(newHelloApp(), Input{
+		Args: []string{"hello", "--help"},
+		Env:  map[string]string{"COLUMNS": "80"},
+	})
+	Golden(t, "testdata/hello_usage.golden", res.Stderr)
+}
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"strconv"
+)
+
+const (
+	defaultUsageWidth = 80
+	minUsageWidth     = 40
+)
+
+// usageOutput returns the writer where usages and errors are printed and the
+// width at which their text is wrapped.
+//
+// Colors are removed when the given writer is not a terminal, when the
+// NO_COLOR environment variable is set or when TERM is dumb. The width is the
+// terminal width, or the COLUMNS environment variable when the writer is not a
+// terminal.
+func usageOutput(w io.Writer, lookupEnv func(string) (string, bool)) (io.Writer, int) {
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+
+	width, isTerminal := 0, false
+	if f, ok := w.(*os.File); ok {
+		width, isTerminal = terminalWidth(f)
+	}
+
+	if !isTerminal {
+		if columns, ok := lookupEnv("COLUMNS"); ok {
+			width, _ = strconv.Atoi(columns)
+		}
+	}
+
+	switch {
+	case width <= 0:
+		width = defaultUsageWidth
+
+	case width < minUsageWidth:
+		width = minUsageWidth
+	}
+
+	noColor, _ := lookupEnv("NO_COLOR")
+	term, _ := lookupEnv("TERM")
+	if !isTerminal || noColor != "" || term == "dumb" {
+		w = &noColorWriter{w: w}
+	}
+	return w, width
+}
+
+// noColorWriter is a writer that removes ANSI escape sequences from what is
+// written in it.
+type noColorWriter struct {
+	w     io.Writer
+	state int
+}
+
+const (
+	noEscape = iota
+	escapeStart
+	escapeSequence
+)
+
+func (w *noColorWriter) Write(p []byte) (int, error) {
+	b := make([]byte, 0, len(p))
+
+	for _, c := range p {
+		switch w.state {
+		case escapeStart:
+			w.state = noEscape
+			if c == '[' {
+				w.state = escapeSequence
+			}
+
+		case escapeSequence:
+			if c >= 0x40 && c <= 0x7e {
+				w.state = noEscape
+			}
+
+		default:
+			if c == '\033' {
+				w.state = escapeStart
+				continue
+			}
+			b = append(b, c)
+		}
+	}
+
+	if _, err := w.w.Write(b); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNoColorWriter(t *testing.T) {
+	b := bytes.NewBuffer(nil)
+	w := &noColorWriter{w: b}
+
+	fmt.Fprintf(w, "%sUsage:%s\n", accentColor, defaultColor)
+	n, err := w.Write([]byte("\033[1"))
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	fmt.Fprint(w, "mfoo", errorColor, "bar", defaultColor)
+
+	require.Equal(t, "Usage:\nfoobar", b.String())
+}
+
+func TestUsageOutput(t *testing.T) {
+	tests := []struct {
+		scenario      string
+		env           map[string]string
+		expectedWidth int
+	}{
+		{
+			scenario:      "default width",
+			expectedWidth: defaultUsageWidth,
+		},
+		{
+			scenario:      "width from columns",
+			env:           map[string]string{"COLUMNS": "120"},
+			expectedWidth: 120,
+		},
+		{
+			scenario:      "minimum width",
+			env:           map[string]string{"COLUMNS": "10"},
+			expectedWidth: minUsageWidth,
+		},
+		{
+			scenario:      "invalid columns",
+			env:           map[string]string{"COLUMNS": "wide"},
+			expectedWidth: defaultUsageWidth,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			b := bytes.NewBuffer(nil)
+
+			w, width := usageOutput(b, func(k string) (string, bool) {
+				v, ok := test.env[k]
+				return v, ok
+			})
+			require.Equal(t, test.expectedWidth, width)
+
+			fmt.Fprint(w, accentColor, "foo", defaultColor)
+			require.Equal(t, "foo", b.String())
+		})
+	}
+}
+
+func TestCommandUsageWidth(t *testing.T) {
+	cmd := &command{
+		help: "Lorem ipsum dolor sit amet, consectetur adipiscing elit, sed do eiusmod tempor.",
+	}
+
+	w := bytes.NewBuffer(nil)
+	commandUsage(&noColorWriter{w: w}, minUsageWidth, "test", cmd, nil, nil)()
+
+	for _, line := range bytes.Split(w.Bytes(), []byte("\n")) {
+		require.LessOrEqual(t, len(line), minUsageWidth)
+	}
+	t.Log(w.String())
+}
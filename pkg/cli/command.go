@@ -1,12 +1,15 @@
 package cli
 
 import (
+	"context"
 	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 )
 
@@ -15,9 +18,18 @@ var (
 )
 
 type command struct {
-	help    string
-	name    string
-	options interface{}
+	help       string
+	name       string
+	options    interface{}
+	persistent interface{}
+	handler    func(context.Context) error
+	before     func(context.Context) error
+	after      func(context.Context, error) error
+	builtin    func() error
+	aliases    []string
+	group      string
+	deprecated string
+	isHidden   bool
 }
 
 func (c *command) Help(h string) Command {
@@ -30,9 +42,84 @@ func (c *command) Options(o interface{}) Command {
 	return c
 }
 
+func (c *command) PersistentOptions(o interface{}) Command {
+	c.persistent = o
+	return c
+}
+
+func (c *command) Run(h func(context.Context) error) Command {
+	c.handler = h
+	return c
+}
+
+func (c *command) Before(h func(context.Context) error) Command {
+	c.before = h
+	return c
+}
+
+func (c *command) After(h func(context.Context, error) error) Command {
+	c.after = h
+	return c
+}
+
+func (c *command) Aliases(names ...string) Command {
+	// The root command does not have a name that aliases could replace.
+	if c.name == "" {
+		return c
+	}
+
+	c.aliases = append(c.aliases, names...)
+	return c
+}
+
+func (c *command) Group(g string) Command {
+	c.group = g
+	return c
+}
+
+func (c *command) Hidden() Command {
+	c.isHidden = true
+	return c
+}
+
+func (c *command) Deprecated(msg string) Command {
+	c.deprecated = msg
+	return c
+}
+
+// lastWord returns the last word of the command name.
+func (c *command) lastWord() string {
+	words := strings.Fields(c.name)
+	if len(words) == 0 {
+		return ""
+	}
+	return words[len(words)-1]
+}
+
 type commandManager struct {
-	out      io.Writer
-	commands map[string]Command
+	out       io.Writer
+	stdout    io.Writer
+	stdin     io.Reader
+	name      string
+	lookupEnv func(string) (string, bool)
+	sources   []Source
+	commands  map[string]Command
+
+	// Whether the --version flag is added to commands.
+	hasVersionFlag bool
+
+	// The options, the --print-config format and the --version format of the
+	// last parsed command.
+	options      []option
+	printConfig  string
+	printVersion string
+}
+
+func (m *commandManager) programName() string {
+	if m.name == "" {
+		return filepath.Base(os.Args[0])
+	}
+	return m.name
 }
 
 func (m *commandManager) register(cmd ...string) Command {
@@ -48,32 +135,350 @@ func (m *commandManager) register(cmd ...string) Command {
 }
 
 func (m *commandManager) parse(args ...string) (string, func(), error) {
-	cmdslice, optsSlice := splitCommand(args)
-	k := commandString(cmdslice...)
+	m.options = nil
+	m.printConfig = ""
+	m.printVersion = ""
 
-	i, ok := m.commands[k]
-	if !ok {
-		err := errNoRootCmd
-		if k != "" {
-			err = fmt.Errorf("unknown command: %s", k)
+	path, positional, flagArgs := m.splitArgs(args)
+	k := commandString(append(path, positional...)...)
+
+	cmd, cmdArgs := m.lookup(path)
+	if cmd == nil {
+		// The version is printed by programs that do not have a root
+		// command.
+		if format, ok := lookupVersionFlag(flagArgs); ok && m.hasVersionFlag && k == "" {
+			m.printVersion = format
+			return "", nil, nil
 		}
-		return "", commandUsageIndex(m.out, m.commands), err
+		return "", m.indexUsage(helpFormat(nil, flagArgs)), unknownCommandError(k, m.commands)
 	}
-	cmd := i.(*command)
+	cmdArgs = append(cmdArgs, positional...)
 
-	programName := filepath.Base(os.Args[0])
-	flags := flag.NewFlagSet(commandString(programName, k), flag.ContinueOnError)
+	flags := flag.NewFlagSet(commandString(m.programName(), cmd.name), flag.ContinueOnError)
 	flags.SetOutput(writerNoop{})
 
-	optsParser := optionParser{flags: flags}
+	optsParser := optionParser{
+		flags:      flags,
+		lookupEnv:  m.lookupEnv,
+		sources:    m.sources,
+		persistent: persistentOptions(m.commands, cmd.name),
+	}
+	optsParser.configFile, _ = lookupFlagValue(flagArgs, configOptionName)
+
 	opts, err := optsParser.parse(cmd.options)
 	if err != nil {
 		return "", nil, fmt.Errorf("parsing options failed: %w", err)
 	}
 
-	usage := commandUsage(m.out, cmd, opts)
+	if !hasOption(opts, printConfigFlagName) {
+		flags.Var(formatFlag{format: &m.printConfig, formats: []string{Text, JSON, Env}}, printConfigFlagName, "")
+	}
+
+	if m.hasVersionFlag && !hasOption(opts, versionFlagName) {
+		flags.Var(formatFlag{format: &m.printVersion, formats: versionFormats}, versionFlagName, "")
+	}
+
+	nonInteractive := false
+	if !hasOption(opts, nonInteractiveFlagName) {
+		flags.BoolVar(&nonInteractive, nonInteractiveFlagName, false, "")
+	}
+
+	usage := m.commandUsage(helpFormat(flags, flagArgs), cmd, opts)
 	flags.Usage = func() {}
-	return cmd.name, usage, flags.Parse(optsSlice)
+	flagArgs = expandShortFlags(flags, flagArgs)
+	if err := checkFlags(flags, opts, flagArgs); err != nil {
+		return cmd.name, usage, err
+	}
+
+	if len(cmdArgs) != 0 && !hasArgs(opts) {
+		return "", m.indexUsage(helpFormat(flags, flagArgs)), unknownCommandError(k, m.commands)
+	}
+
+	if err := flags.Parse(flagArgs); err != nil {
+		return cmd.name, usage, redactFlagsError(flags, err)
+	}
+
+	// Options are not validated when only the version is printed.
+	if m.printVersion != "" {
+		return cmd.name, usage, nil
+	}
+
+	if err := setArgs(opts, append(cmdArgs, flags.Args()...)); err != nil {
+		return cmd.name, usage, err
+	}
+
+	if !nonInteractive {
+		if err := promptOptions(m.stdin, m.out, opts); err != nil {
+			return cmd.name, usage, err
+		}
+	}
+
+	if err := validateOptions(opts); err != nil {
+		return cmd.name, usage, err
+	}
+
+	m.options = opts
+	return cmd.name, usage, nil
+}
+
+// commandUsage returns the function that prints the usage of the given command
+// in the given help format.
+func (m *commandManager) commandUsage(format string, cmd *command, opts []option) func() {
+	if format == JSON {
+		return commandSchemaUsage(m.stdoutOrOut(), m.programName(), cmd.name, m.commands)
+	}
+
+	w, width := usageOutput(m.out, m.lookupEnv)
+	return commandUsage(w, width, m.programName(), cmd, opts, subcommands(m.commands, cmd.name))
+}
+
+// indexUsage returns the function that prints the registered commands in the
+// given help format.
+func (m *commandManager) indexUsage(format string) func() {
+	if format == JSON {
+		return commandSchemaUsage(m.stdoutOrOut(), m.programName(), "", m.commands)
+	}
+
+	w, width := usageOutput(m.out, m.lookupEnv)
+	return commandUsageIndex(w, width, m.programName(), m.commands)
+}
+
+// stdoutOrOut returns the writer where machine-readable usages are written.
+func (m *commandManager) stdoutOrOut() io.Writer {
+	if m.stdout == nil {
+		return m.out
+	}
+	return m.stdout
+}
+
+// splitArgs walks the registered command tree with the given args. It returns
+// the command path, the positional arguments and the flags with their values.
+//
+// Flags can be placed before, between or after command names. A flag takes
+// the next argument as value when it is a non-boolean option of the command
+// resolved so far or, when undefined there, of one of its subcommands.
+// Arguments following "--" are positional.
+func (m *commandManager) splitArgs(args []string) (path, positional, flagArgs []string) {
+	flagSets := make(map[string]*flag.FlagSet)
+	expectsValue := func(arg string) bool {
+		for _, c := range m.commandsUnder(path) {
+			flags, ok := flagSets[c.name]
+			if !ok {
+				flags = commandFlags(m.commands, c)
+				flagSets[c.name] = flags
+			}
+
+			if expects, ok := flagExpectsValue(flags, arg); ok {
+				return expects
+			}
+		}
+		return false
+	}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		switch {
+		case arg == "--":
+			return path, append(positional, args[i+1:]...), flagArgs
+
+		case strings.HasPrefix(arg, "-") && arg != "-":
+			flagArgs = append(flagArgs, arg)
+			if i+1 < len(args) && expectsValue(arg) {
+				i++
+				flagArgs = append(flagArgs, args[i])
+			}
+
+		case len(positional) == 0 && m.isCommandPath(append(path, arg)):
+			path = append(path, arg)
+
+		case len(positional) == 0 && m.resolveAlias(path, arg) != "":
+			path = append(path, m.resolveAlias(path, arg))
+
+		default:
+			positional = append(positional, arg)
+		}
+	}
+
+	return path, positional, flagArgs
+}
+
+// isCommandPath reports whether the given path leads to a registered command.
+func (m *commandManager) isCommandPath(path []string) bool {
+	for name := range m.commands {
+		if words := strings.Fields(name); len(words) >= len(path) && slices.Equal(words[:len(path)], path) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveAlias returns the last word of the registered command under the given
+// path that has the given alias, or an empty string when there is none.
+func (m *commandManager) resolveAlias(path []string, alias string) string {
+	for name, c := range m.commands {
+		words := strings.Fields(name)
+		if len(words) == len(path)+1 &&
+			slices.Equal(words[:len(path)], path) &&
+			containsString(c.(*command).aliases, alias) {
+			return words[len(path)]
+		}
+	}
+	return ""
+}
+
+// commandsUnder returns the registered command that matches the given path
+// followed by the registered commands that are under the path, sorted by
+// name.
+func (m *commandManager) commandsUnder(path []string) []*command {
+	var cmds []*command
+	if c, _ := m.lookup(path); c != nil {
+		cmds = append(cmds, c)
+	}
+
+	var under []*command
+	for name, c := range m.commands {
+		words := strings.Fields(name)
+		if len(words) > len(path) && slices.Equal(words[:len(path)], path) {
+			under = append(under, c.(*command))
+		}
+	}
+	sort.Slice(under, func(i, j int) bool {
+		return under[i].name < under[j].name
+	})
+
+	return append(cmds, under...)
+}
+
+// commandFlags returns a flag set with the options of the given command. It
+// is only used to look up flags: option values are not loaded.
+func commandFlags(cmds map[string]Command, c *command) *flag.FlagSet {
+	flags := flag.NewFlagSet(c.name, flag.ContinueOnError)
+
+	p := optionParser{persistent: persistentOptions(cmds, c.name)}
+	if err := p.collect(c.options); err != nil {
+		return flags
+	}
+
+	for _, o := range p.options {
+		if !o.isArg {
+			flags.Var(o, o.name, o.help)
+		}
+		if o.short != "" {
+			flags.Var(o, o.short, o.help)
+		}
+		for _, name := range o.deprecatedFlags {
+			flags.Var(o, name, o.help)
+		}
+	}
+	return flags
+}
+
+// flagExpectsValue reports whether the given flag argument takes the next
+// argument as value. ok is false when the flag is not defined in the given
+// flag set.
+func flagExpectsValue(flags *flag.FlagSet, arg string) (expects, ok bool) {
+	if strings.Contains(arg, "=") {
+		return false, true
+	}
+
+	name := strings.TrimPrefix(arg[1:], "-")
+	if f := flags.Lookup(name); f != nil {
+		return !isBoolFlag(f), true
+	}
+
+	if strings.HasPrefix(arg, "--") {
+		return false, false
+	}
+
+	_, expects, ok = splitShortFlags(flags, name)
+	return expects, ok
+}
+
+// lookup returns the registered command with the longest name that prefixes
+// the given command slice, and the remaining elements that are positional
+// arguments.
+func (m *commandManager) lookup(cmdslice []string) (*command, []string) {
+	for i := len(cmdslice); i >= 0; i-- {
+		if c, ok := m.commands[commandString(cmdslice[:i]...)]; ok {
+			return c.(*command), cmdslice[i:]
+		}
+	}
+	return nil, nil
+}
+
+// persistentOptions returns the persistent option receivers of the named
+// command and of its registered ancestors, from the root to the command.
+// Built-in commands, such as the completion or version commands, do not
+// inherit persistent options since they are not used by their handlers.
+func persistentOptions(cmds map[string]Command, name string) []interface{} {
+	if c, ok := cmds[name]; ok && c.(*command).builtin != nil {
+		return nil
+	}
+
+	var receivers []interface{}
+	for _, c := range commandLineage(cmds, name) {
+		if c.persistent != nil {
+			receivers = append(receivers, c.persistent)
+		}
+	}
+	return receivers
+}
+
+// commandLineage returns the named command and its registered ancestors, from
+// the root to the command.
+func commandLineage(cmds map[string]Command, name string) []*command {
+	var lineage []*command
+
+	words := strings.Fields(name)
+	for i := 0; i <= len(words); i++ {
+		if c, ok := cmds[strings.Join(words[:i], " ")]; ok {
+			lineage = append(lineage, c.(*command))
+		}
+	}
+	return lineage
+}
+
+// visibleCommands returns the registered commands that are not hidden.
+func visibleCommands(cmds map[string]Command) map[string]*command {
+	visible := make(map[string]*command, len(cmds))
+	for name, c := range cmds {
+		if cmd := c.(*command); !cmd.isHidden {
+			visible[name] = cmd
+		}
+	}
+	return visible
+}
+
+// parentCommandName returns the name of the closest ancestor of the named
+// command within the given commands. The root command name is returned when
+// there is none.
+func parentCommandName(cmds map[string]*command, name string) string {
+	words := strings.Fields(name)
+	for i := len(words) - 1; i > 0; i-- {
+		if p := strings.Join(words[:i], " "); cmds[p] != nil {
+			return p
+		}
+	}
+	return ""
+}
+
+// subcommands returns the visible commands whose closest visible ancestor is
+// the named command, sorted by name.
+func subcommands(cmds map[string]Command, name string) []*command {
+	visible := visibleCommands(cmds)
+
+	var subs []*command
+	for n, c := range visible {
+		if n != "" && n != name && parentCommandName(visible, n) == name {
+			subs = append(subs, c)
+		}
+	}
+
+	sort.Slice(subs, func(i, j int) bool {
+		return subs[i].name < subs[j].name
+	})
+	return subs
 }
 
 func commandString(cmd ...string) string {
@@ -87,22 +492,6 @@ func commandString(cmd ...string) string {
 	return strings.Join(clean, " ")
 }
 
-func splitCommand(args []string) (cmd, opts []string) {
-	end := commandEndIndex(args)
-	return args[:end], args[end:]
-}
-
-func commandEndIndex(args []string) int {
-	i := 0
-	for i < len(args) {
-		if strings.HasPrefix(args[i], "-") {
-			return i
-		}
-		i++
-	}
-	return i
-}
-
 type writerNoop struct{}
 
 func (w writerNoop) Write([]byte) (int, error) {
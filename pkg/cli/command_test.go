@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -134,38 +135,286 @@ func TestCommandeString(t *testing.T) {
 	}
 }
 
-func TestCommandEndIndex(t *testing.T) {
+func TestCommandManagerSplitArgs(t *testing.T) {
+	rootOpts := struct {
+		Verbose bool   `short:"v"`
+		Output  string `short:"o"`
+	}{}
+
+	upOpts := struct {
+		Steps int
+	}{}
+
+	m := commandManager{}
+	m.register().Options(&rootOpts)
+	m.register("migrate", "up").Options(&upOpts)
+	m.register("migrate", "down")
+
 	tests := []struct {
-		scenario      string
-		args          []string
-		expectedIndex int
+		scenario           string
+		args               []string
+		expectedPath       []string
+		expectedPositional []string
+		expectedFlags      []string
 	}{
 		{
-			scenario:      "args with an option at the beginning",
-			args:          []string{"-v", "foo", "bar"},
-			expectedIndex: 0,
+			scenario:      "flags only",
+			args:          []string{"-v", "-o", "file"},
+			expectedFlags: []string{"-v", "-o", "file"},
+		},
+		{
+			scenario:      "flags before command",
+			args:          []string{"--verbose", "migrate", "up"},
+			expectedPath:  []string{"migrate", "up"},
+			expectedFlags: []string{"--verbose"},
+		},
+		{
+			scenario:      "flags between command names",
+			args:          []string{"migrate", "-o", "up", "up", "--steps=2"},
+			expectedPath:  []string{"migrate", "up"},
+			expectedFlags: []string{"-o", "up", "--steps=2"},
+		},
+		{
+			scenario:      "subcommand flag before command",
+			args:          []string{"--steps", "3", "migrate", "up"},
+			expectedPath:  []string{"migrate", "up"},
+			expectedFlags: []string{"--steps", "3"},
 		},
 		{
-			scenario:      "args with an option at the end",
-			args:          []string{"foo", "bar", "-v"},
-			expectedIndex: 2,
+			scenario:      "combined short flags with value",
+			args:          []string{"-vo", "migrate", "migrate", "down"},
+			expectedPath:  []string{"migrate", "down"},
+			expectedFlags: []string{"-vo", "migrate"},
 		},
 		{
-			scenario:      "args with an option at the middle",
-			args:          []string{"foo", "foo-bar", "-v", "bar"},
-			expectedIndex: 2,
+			scenario:           "positional arguments stop the command path",
+			args:               []string{"migrate", "sideways", "up"},
+			expectedPath:       []string{"migrate"},
+			expectedPositional: []string{"sideways", "up"},
 		},
 		{
-			scenario:      "args without option",
-			args:          []string{"foo", "foo-bar", "bar"},
-			expectedIndex: 3,
+			scenario:           "end of options marker",
+			args:               []string{"migrate", "-v", "--", "up", "-o"},
+			expectedPath:       []string{"migrate"},
+			expectedPositional: []string{"up", "-o"},
+			expectedFlags:      []string{"-v"},
+		},
+		{
+			scenario:           "dash is positional",
+			args:               []string{"-"},
+			expectedPositional: []string{"-"},
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.scenario, func(t *testing.T) {
-			idx := commandEndIndex(test.args)
-			require.Equal(t, test.expectedIndex, idx)
+			path, positional, flags := m.splitArgs(test.args)
+			require.Equal(t, test.expectedPath, path)
+			require.Equal(t, test.expectedPositional, positional)
+			require.Equal(t, test.expectedFlags, flags)
+		})
+	}
+}
+
+func TestCommandManagerFlagsAnywhere(t *testing.T) {
+	opts := struct {
+		Verbose bool
+		Steps   int
+		Names   []string `arg:"rest"`
+	}{}
+
+	m := commandManager{}
+	m.register("migrate", "up").Options(&opts)
+
+	cmd, _, err := m.parse("--verbose", "migrate", "--steps", "2", "up", "a", "--", "-b")
+	require.NoError(t, err)
+	require.Equal(t, "migrate up", cmd)
+	require.True(t, opts.Verbose)
+	require.Equal(t, 2, opts.Steps)
+	require.Equal(t, []string{"a", "-b"}, opts.Names)
+}
+
+func TestCommandManagerAliases(t *testing.T) {
+	opts := struct {
+		Steps int
+	}{}
+
+	m := commandManager{}
+	m.register("migrate").Aliases("m", "mig")
+	m.register("migrate", "up").Aliases("u").Options(&opts)
+
+	cmd, _, err := m.parse("m", "--steps", "2", "u")
+	require.NoError(t, err)
+	require.Equal(t, "migrate up", cmd)
+	require.Equal(t, 2, opts.Steps)
+
+	cmd, _, err = m.parse("mig")
+	require.NoError(t, err)
+	require.Equal(t, "migrate", cmd)
+
+	_, _, err = m.parse("u")
+	require.Error(t, err)
+	t.Log("error:", err)
+}
+
+func TestCommandManagerPersistentOptions(t *testing.T) {
+	type globalOptions struct {
+		Verbose bool `short:"v"`
+		Region  string
+	}
+
+	type upOptions struct {
+		Steps int
+	}
+
+	tests := []struct {
+		scenario       string
+		args           []string
+		env            map[string]string
+		expectedCmd    string
+		expectedGlobal globalOptions
+		expectedUp     upOptions
+		err            bool
+	}{
+		{
+			scenario:       "root",
+			args:           []string{"-v"},
+			expectedGlobal: globalOptions{Verbose: true},
+		},
+		{
+			scenario:       "before the subcommand",
+			args:           []string{"--region", "eu", "migrate", "up", "--steps", "2"},
+			expectedCmd:    "migrate up",
+			expectedGlobal: globalOptions{Region: "eu"},
+			expectedUp:     upOptions{Steps: 2},
+		},
+		{
+			scenario:       "after the subcommand",
+			args:           []string{"migrate", "up", "-v", "--region", "eu"},
+			expectedCmd:    "migrate up",
+			expectedGlobal: globalOptions{Verbose: true, Region: "eu"},
+		},
+		{
+			scenario:       "from env",
+			args:           []string{"migrate"},
+			env:            map[string]string{"REGION": "us"},
+			expectedCmd:    "migrate",
+			expectedGlobal: globalOptions{Region: "us"},
+		},
+		{
+			scenario: "subcommand option on parent returns an error",
+			args:     []string{"migrate", "--steps", "2"},
+			err:      true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			var global globalOptions
+			var up upOptions
+
+			m := commandManager{
+				out: writerNoop{},
+				lookupEnv: func(k string) (string, bool) {
+					v, ok := test.env[k]
+					return v, ok
+				},
+			}
+			m.register().PersistentOptions(&global)
+			m.register("migrate")
+			m.register("migrate", "up").Options(&up)
+
+			cmd, _, err := m.parse(test.args...)
+			if test.err {
+				require.Error(t, err)
+				t.Log("error:", err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expectedCmd, cmd)
+			require.Equal(t, test.expectedGlobal, global)
+			require.Equal(t, test.expectedUp, up)
+		})
+	}
+}
+
+func TestCommandManagerPersistentOptionsConflict(t *testing.T) {
+	tests := []struct {
+		scenario   string
+		options    interface{}
+		persistent interface{}
+	}{
+		{
+			scenario: "name defined by the command",
+			options: &struct {
+				Region string
+			}{},
+			persistent: &struct {
+				Region string
+			}{},
+		},
+		{
+			scenario: "argument",
+			persistent: &struct {
+				File string `arg:"0"`
+			}{},
+		},
+		{
+			scenario:   "not a struct pointer",
+			persistent: struct{}{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			m := commandManager{out: writerNoop{}}
+			m.register().PersistentOptions(test.persistent)
+			m.register("deploy").Options(test.options)
+
+			_, _, err := m.parse("deploy")
+			require.Error(t, err)
+			t.Log("error:", err)
+		})
+	}
+}
+
+func TestBuiltinCommandsIgnorePersistentOptions(t *testing.T) {
+	tests := []struct {
+		scenario string
+		args     []string
+		code     int
+	}{
+		{
+			scenario: "completion",
+			args:     []string{"completion", "bash"},
+		},
+		{
+			scenario: "completion usage",
+			args:     []string{"completion", "bash", "-h"},
+			code:     -1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			stderr := bytes.NewBuffer(nil)
+			code := 0
+
+			app := New("test")
+			app.Args = test.args
+			app.Stdout = writerNoop{}
+			app.Stderr = stderr
+			app.LookupEnv = func(string) (string, bool) { return "", false }
+			app.Exit = func(c int) { code = c }
+			app.Register().PersistentOptions(&struct {
+				Region string `validate:"required" prompt:"Enter the region"`
+			}{})
+			app.RegisterCompletion()
+
+			app.Load()
+			require.Equal(t, test.code, code)
+			require.NotContains(t, stderr.String(), "region")
+			require.NotContains(t, stderr.String(), "Global options:")
 		})
 	}
 }
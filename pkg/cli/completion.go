@@ -0,0 +1,625 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Supported completion shells.
+const (
+	Bash = "bash"
+	Zsh  = "zsh"
+	Fish = "fish"
+)
+
+var (
+	completionShells = []string{Bash, Zsh, Fish}
+	funcNameReplacer = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+)
+
+// Completion writes the completion script of the commands registered in the
+// default app for the given shell. See App.Completion.
+func Completion(w io.Writer, shell string) error {
+	return defaultApp.Completion(w, shell)
+}
+
+// RegisterCompletion registers the completion commands in the default app. See
+// App.RegisterCompletion.
+func RegisterCompletion() {
+	defaultApp.RegisterCompletion()
+}
+
+// Completion writes the completion script of the registered commands for the
+// given shell. Supported shells are bash, zsh and fish.
+func (a *App) Completion(w io.Writer, shell string) error {
+	return a.commandManager().completion(w, shell)
+}
+
+// RegisterCompletion registers the "completion bash", "completion zsh" and
+// "completion fish" commands that print the completion script for the
+// corresponding shell on the app standard output when loaded.
+func (a *App) RegisterCompletion() {
+	for _, shell := range completionShells {
+		shell := shell
+
+		c := a.Register("completion", shell).
+			Help(fmt.Sprintf("Generates the %s completion script.", shell)).(*command)
+		c.builtin = func() error {
+			return a.Completion(a.stdout(), shell)
+		}
+	}
+}
+
+type completionCommand struct {
+	name        string
+	subcommands []string
+	aliases     map[string]string
+	options     []completionOption
+}
+
+type completionOption struct {
+	name   string
+	short  string
+	help   string
+	isBool bool
+	values []string
+}
+
+func (m *commandManager) completion(w io.Writer, shell string) error {
+	cmds, err := completionCommands(m.commands)
+	if err != nil {
+		return err
+	}
+
+	programName := m.programName()
+
+	switch shell {
+	case Bash:
+		writeBashCompletion(w, programName, cmds)
+
+	case Zsh:
+		writeZshCompletion(w, programName, cmds)
+
+	case Fish:
+		writeFishCompletion(w, programName, cmds)
+
+	default:
+		return fmt.Errorf("unsupported completion shell: %q", shell)
+	}
+	return nil
+}
+
+func completionCommands(cmds map[string]Command) ([]completionCommand, error) {
+	nodes := map[string]*completionCommand{"": {}}
+	node := func(name string) *completionCommand {
+		n, ok := nodes[name]
+		if !ok {
+			n = &completionCommand{name: name}
+			nodes[name] = n
+		}
+		return n
+	}
+
+	for name, c := range cmds {
+		if c.(*command).isHidden {
+			continue
+		}
+
+		words := strings.Fields(name)
+		for i, word := range words {
+			parent := node(strings.Join(words[:i], " "))
+			if !containsString(parent.subcommands, word) {
+				parent.subcommands = append(parent.subcommands, word)
+			}
+		}
+
+		if aliases := c.(*command).aliases; len(words) != 0 && len(aliases) != 0 {
+			parent := node(strings.Join(words[:len(words)-1], " "))
+			if parent.aliases == nil {
+				parent.aliases = make(map[string]string, len(aliases))
+			}
+			for _, alias := range aliases {
+				parent.aliases[alias] = words[len(words)-1]
+			}
+		}
+
+		p := optionParser{persistent: persistentOptions(cmds, name)}
+		if err := p.collect(c.(*command).options); err != nil {
+			return nil, fmt.Errorf("parsing %q options failed: %w", name, err)
+		}
+
+		n := node(name)
+		for _, o := range p.options {
+			if o.isHidden || o.isArg {
+				continue
+			}
+
+			n.options = append(n.options, completionOption{
+				name:   o.name,
+				short:  o.short,
+				help:   strings.Join(strings.Fields(o.help), " "),
+				isBool: o.IsBoolFlag(),
+				values: optionEnumValues(o),
+			})
+		}
+		n.options = append(n.options, completionOption{
+			name:   "help",
+			help:   "Show help.",
+			isBool: true,
+		})
+	}
+
+	res := make([]completionCommand, 0, len(nodes))
+	for _, n := range nodes {
+		sort.Strings(n.subcommands)
+		res = append(res, *n)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].name < res[j].name
+	})
+	return res, nil
+}
+
+func writeBashCompletion(w io.Writer, programName string, cmds []completionCommand) {
+	funcName := completionFuncName(programName)
+
+	fmt.Fprintf(w, "# bash completion for %s\n\n", programName)
+	writeShellCompletionHelpers(w, funcName, cmds)
+	fmt.Fprintf(w, "%s() {\n", funcName)
+	fmt.Fprintln(w, `    local cur="${COMP_WORDS[COMP_CWORD]}"`)
+	fmt.Fprintln(w, `    local prev="${COMP_WORDS[COMP_CWORD-1]}"`)
+	fmt.Fprintln(w, `    local cmd="" positional="" subcommands="" options="" word sub i`)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `    for ((i = 1; i < COMP_CWORD; i++)); do`)
+	fmt.Fprintln(w, `        word="${COMP_WORDS[i]}"`)
+	fmt.Fprintln(w, `        case "$word" in`)
+	fmt.Fprintln(w, `        --)`)
+	fmt.Fprintln(w, `            positional=1`)
+	fmt.Fprintln(w, `            break`)
+	fmt.Fprintln(w, `            ;;`)
+	fmt.Fprintln(w, `        -*=*)`)
+	fmt.Fprintln(w, `            ;;`)
+	fmt.Fprintln(w, `        -?*)`)
+	fmt.Fprintf(w, "            if [[ \"${COMP_WORDS[i+1]}\" != \"=\" ]] && %s_value_flag \"$cmd\" \"$word\"; then\n", funcName)
+	fmt.Fprintln(w, `                i=$((i + 1))`)
+	fmt.Fprintln(w, `            fi`)
+	fmt.Fprintln(w, `            ;;`)
+	fmt.Fprintln(w, `        =)`)
+	fmt.Fprintln(w, `            # Bash splits --flag=value words.`)
+	fmt.Fprintln(w, `            i=$((i + 1))`)
+	fmt.Fprintln(w, `            ;;`)
+	fmt.Fprintln(w, `        *)`)
+	fmt.Fprintf(w, "            if [[ -z \"$positional\" ]] && sub=\"$(%s_subcommand \"$cmd\" \"$word\")\"; then\n", funcName)
+	fmt.Fprintln(w, `                cmd="${cmd:+$cmd }$sub"`)
+	fmt.Fprintln(w, `            else`)
+	fmt.Fprintln(w, `                positional=1`)
+	fmt.Fprintln(w, `            fi`)
+	fmt.Fprintln(w, `            ;;`)
+	fmt.Fprintln(w, `        esac`)
+	fmt.Fprintln(w, `    done`)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `    case "$cmd" in`)
+
+	for _, c := range cmds {
+		fmt.Fprintf(w, "    %s)\n", shellQuote(c.name))
+
+		if valueOpts := valueOptions(c.options); len(valueOpts) != 0 {
+			fmt.Fprintln(w, `        case "$prev" in`)
+			for _, o := range valueOpts {
+				fmt.Fprintf(w, "        %s)\n", optionPatterns(o))
+				if len(o.values) != 0 {
+					fmt.Fprintf(w, "            COMPREPLY=($(compgen -W %s -- \"$cur\"))\n", shellQuote(strings.Join(o.values, " ")))
+				} else {
+					fmt.Fprintln(w, `            COMPREPLY=()`)
+				}
+				fmt.Fprintln(w, `            return`)
+				fmt.Fprintln(w, `            ;;`)
+			}
+			fmt.Fprintln(w, `        esac`)
+		}
+
+		if len(c.subcommands) != 0 {
+			fmt.Fprintf(w, "        subcommands=%s\n", shellQuote(strings.Join(c.subcommands, " ")))
+		}
+		if len(c.options) != 0 {
+			fmt.Fprintf(w, "        options=%s\n", shellQuote(strings.Join(optionNames(c.options), " ")))
+		}
+		fmt.Fprintln(w, `        ;;`)
+	}
+
+	fmt.Fprintln(w, `    esac`)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `    if [[ -z "$positional" && "$cur" != -* && -n "$subcommands" ]]; then`)
+	fmt.Fprintln(w, `        COMPREPLY=($(compgen -W "$subcommands" -- "$cur"))`)
+	fmt.Fprintln(w, `    else`)
+	fmt.Fprintln(w, `        COMPREPLY=($(compgen -W "$options" -- "$cur"))`)
+	fmt.Fprintln(w, `    fi`)
+	fmt.Fprintln(w, `}`)
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "complete -o default -F %s %s\n", funcName, programName)
+}
+
+func writeZshCompletion(w io.Writer, programName string, cmds []completionCommand) {
+	funcName := completionFuncName(programName)
+
+	fmt.Fprintf(w, "#compdef %s\n\n", programName)
+	fmt.Fprintf(w, "# zsh completion for %s\n\n", programName)
+	writeShellCompletionHelpers(w, funcName, cmds)
+	fmt.Fprintf(w, "%s() {\n", funcName)
+	fmt.Fprintln(w, `    local cur="${words[CURRENT]}"`)
+	fmt.Fprintln(w, `    local prev="${words[CURRENT-1]}"`)
+	fmt.Fprintln(w, `    local cmd="" positional="" word sub i`)
+	fmt.Fprintln(w, `    local -a subcommands options`)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `    for ((i = 2; i < CURRENT; i++)); do`)
+	fmt.Fprintln(w, `        word="${words[i]}"`)
+	fmt.Fprintln(w, `        case "$word" in`)
+	fmt.Fprintln(w, `        --)`)
+	fmt.Fprintln(w, `            positional=1`)
+	fmt.Fprintln(w, `            break`)
+	fmt.Fprintln(w, `            ;;`)
+	fmt.Fprintln(w, `        -*=*)`)
+	fmt.Fprintln(w, `            ;;`)
+	fmt.Fprintln(w, `        -?*)`)
+	fmt.Fprintf(w, "            if %s_value_flag \"$cmd\" \"$word\"; then\n", funcName)
+	fmt.Fprintln(w, `                i=$((i + 1))`)
+	fmt.Fprintln(w, `            fi`)
+	fmt.Fprintln(w, `            ;;`)
+	fmt.Fprintln(w, `        *)`)
+	fmt.Fprintf(w, "            if [[ -z \"$positional\" ]] && sub=\"$(%s_subcommand \"$cmd\" \"$word\")\"; then\n", funcName)
+	fmt.Fprintln(w, `                cmd="${cmd:+$cmd }$sub"`)
+	fmt.Fprintln(w, `            else`)
+	fmt.Fprintln(w, `                positional=1`)
+	fmt.Fprintln(w, `            fi`)
+	fmt.Fprintln(w, `            ;;`)
+	fmt.Fprintln(w, `        esac`)
+	fmt.Fprintln(w, `    done`)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `    case "$cmd" in`)
+
+	for _, c := range cmds {
+		fmt.Fprintf(w, "    %s)\n", shellQuote(c.name))
+
+		if valueOpts := valueOptions(c.options); len(valueOpts) != 0 {
+			fmt.Fprintln(w, `        case "$prev" in`)
+			for _, o := range valueOpts {
+				fmt.Fprintf(w, "        %s)\n", optionPatterns(o))
+				if len(o.values) != 0 {
+					fmt.Fprintf(w, "            compadd -- %s\n", shellQuoteAll(o.values))
+				} else {
+					fmt.Fprintln(w, `            _files`)
+				}
+				fmt.Fprintln(w, `            return`)
+				fmt.Fprintln(w, `            ;;`)
+			}
+			fmt.Fprintln(w, `        esac`)
+		}
+
+		if len(c.subcommands) != 0 {
+			fmt.Fprintf(w, "        subcommands=(%s)\n", shellQuoteAll(c.subcommands))
+		}
+		if len(c.options) != 0 {
+			fmt.Fprintf(w, "        options=(%s)\n", shellQuoteAll(optionNames(c.options)))
+		}
+		fmt.Fprintln(w, `        ;;`)
+	}
+
+	fmt.Fprintln(w, `    esac`)
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `    if [[ -z "$positional" && "$cur" != -* && ${#subcommands} -ne 0 ]]; then`)
+	fmt.Fprintln(w, `        compadd -- "${subcommands[@]}"`)
+	fmt.Fprintln(w, `    else`)
+	fmt.Fprintln(w, `        compadd -- "${options[@]}"`)
+	fmt.Fprintln(w, `    fi`)
+	fmt.Fprintln(w, `}`)
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "if [[ \"${funcstack[1]}\" == %s ]]; then\n", funcName)
+	fmt.Fprintf(w, "    %s \"$@\"\n", funcName)
+	fmt.Fprintln(w, `else`)
+	fmt.Fprintf(w, "    compdef %s %s\n", funcName, programName)
+	fmt.Fprintln(w, `fi`)
+}
+
+func writeFishCompletion(w io.Writer, programName string, cmds []completionCommand) {
+	funcName := completionFuncName(programName)
+
+	fmt.Fprintf(w, "# fish completion for %s\n\n", programName)
+	writeFishCompletionHelpers(w, funcName, cmds)
+	fmt.Fprintf(w, "function %s_using_command\n", funcName)
+	fmt.Fprintln(w, `    set -l cmd`)
+	fmt.Fprintln(w, `    set -l sub`)
+	fmt.Fprintln(w, `    set -l positional false`)
+	fmt.Fprintln(w, `    set -l skip false`)
+	fmt.Fprintln(w, `    for token in (commandline -opc)[2..-1]`)
+	fmt.Fprintln(w, `        if test $skip = true`)
+	fmt.Fprintln(w, `            set skip false`)
+	fmt.Fprintln(w, `            continue`)
+	fmt.Fprintln(w, `        end`)
+	fmt.Fprintln(w, `        switch $token`)
+	fmt.Fprintln(w, `            case --`)
+	fmt.Fprintln(w, `                set positional true`)
+	fmt.Fprintln(w, `                break`)
+	fmt.Fprintln(w, `            case -`)
+	fmt.Fprintln(w, `                set positional true`)
+	fmt.Fprintln(w, `            case '-*=*'`)
+	fmt.Fprintln(w, `            case '-*'`)
+	fmt.Fprintf(w, "                if %s_value_flag \"$cmd\" $token\n", funcName)
+	fmt.Fprintln(w, `                    set skip true`)
+	fmt.Fprintln(w, `                end`)
+	fmt.Fprintln(w, `            case '*'`)
+	fmt.Fprintf(w, "                if test $positional = false; and set sub (%s_subcommand \"$cmd\" $token)\n", funcName)
+	fmt.Fprintln(w, `                    set -a cmd $sub`)
+	fmt.Fprintln(w, `                else`)
+	fmt.Fprintln(w, `                    set positional true`)
+	fmt.Fprintln(w, `                end`)
+	fmt.Fprintln(w, `        end`)
+	fmt.Fprintln(w, `    end`)
+	fmt.Fprintln(w, `    test "$argv[1]" = "$cmd"; or return 1`)
+	fmt.Fprintln(w, `    test (count $argv) -lt 2; or test $positional = false`)
+	fmt.Fprintln(w, `end`)
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "complete -c %s -f\n", programName)
+
+	for _, c := range cmds {
+		fmt.Fprintln(w)
+
+		for _, sub := range c.subcommands {
+			fmt.Fprintf(w, "complete -c %s -n %s -a %s\n",
+				programName,
+				fishQuote(fmt.Sprintf("%s_using_command %s subcommand", funcName, fishQuote(c.name))),
+				fishQuote(sub),
+			)
+		}
+
+		for _, o := range c.options {
+			fmt.Fprintf(w, "complete -c %s -n %s -l %s",
+				programName,
+				fishQuote(fmt.Sprintf("%s_using_command %s", funcName, fishQuote(c.name))),
+				fishQuote(o.name),
+			)
+
+			if o.short != "" {
+				fmt.Fprintf(w, " -s %s", fishQuote(o.short))
+			}
+
+			switch {
+			case len(o.values) != 0:
+				fmt.Fprintf(w, " -x -a %s", fishQuote(strings.Join(o.values, " ")))
+
+			case !o.isBool:
+				fmt.Fprint(w, " -r -F")
+			}
+
+			if o.help != "" {
+				fmt.Fprintf(w, " -d %s", fishQuote(o.help))
+			}
+			fmt.Fprintln(w)
+		}
+	}
+}
+
+// writeShellCompletionHelpers writes the bash and zsh functions used to walk the
+// command line words the same way the program args are split: the subcommand
+// function resolves a subcommand or an alias under a command, and the value
+// flag function reports whether a flag takes the next word as value.
+func writeShellCompletionHelpers(w io.Writer, funcName string, cmds []completionCommand) {
+	fmt.Fprintf(w, "%s_subcommand() {\n", funcName)
+	fmt.Fprintln(w, `    case "$1" in`)
+	for _, c := range cmds {
+		subs := subcommandPatterns(c)
+		if len(subs) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(w, "    %s)\n", shellQuote(c.name))
+		fmt.Fprintln(w, `        case "$2" in`)
+		for _, sub := range subs {
+			fmt.Fprintf(w, "        %s) echo %s; return ;;\n", strings.Join(shellQuoteSlice(sub.words), "|"), shellQuote(sub.name))
+		}
+		fmt.Fprintln(w, `        esac`)
+		fmt.Fprintln(w, `        ;;`)
+	}
+	fmt.Fprintln(w, `    esac`)
+	fmt.Fprintln(w, `    return 1`)
+	fmt.Fprintln(w, `}`)
+	fmt.Fprintln(w)
+
+	fmt.Fprintf(w, "%s_value_flag() {\n", funcName)
+	fmt.Fprintln(w, `    case "$1" in`)
+	for _, c := range cmds {
+		opts := valueFlagOptions(cmds, c)
+		if len(opts) == 0 {
+			continue
+		}
+
+		patterns := make([]string, 0, len(opts))
+		for _, o := range opts {
+			patterns = append(patterns, optionPatterns(o))
+		}
+
+		fmt.Fprintf(w, "    %s)\n", shellQuote(c.name))
+		fmt.Fprintln(w, `        case "$2" in`)
+		fmt.Fprintf(w, "        %s) return ;;\n", strings.Join(patterns, "|"))
+		fmt.Fprintln(w, `        esac`)
+		fmt.Fprintln(w, `        ;;`)
+	}
+	fmt.Fprintln(w, `    esac`)
+	fmt.Fprintln(w, `    return 1`)
+	fmt.Fprintln(w, `}`)
+	fmt.Fprintln(w)
+}
+
+// writeFishCompletionHelpers writes the fish equivalent of the functions
+// written by writeShellCompletionHelpers.
+func writeFishCompletionHelpers(w io.Writer, funcName string, cmds []completionCommand) {
+	fmt.Fprintf(w, "function %s_subcommand\n", funcName)
+	fmt.Fprintln(w, `    switch $argv[1]`)
+	for _, c := range cmds {
+		subs := subcommandPatterns(c)
+		if len(subs) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(w, "        case %s\n", fishQuote(c.name))
+		fmt.Fprintln(w, `            switch $argv[2]`)
+		for _, sub := range subs {
+			fmt.Fprintf(w, "                case %s\n", strings.Join(fishQuoteSlice(sub.words), " "))
+			fmt.Fprintf(w, "                    echo %s\n", fishQuote(sub.name))
+			fmt.Fprintln(w, `                    return 0`)
+		}
+		fmt.Fprintln(w, `            end`)
+	}
+	fmt.Fprintln(w, `    end`)
+	fmt.Fprintln(w, `    return 1`)
+	fmt.Fprintln(w, `end`)
+	fmt.Fprintln(w)
+
+	fmt.Fprintf(w, "function %s_value_flag\n", funcName)
+	fmt.Fprintln(w, `    switch $argv[1]`)
+	for _, c := range cmds {
+		opts := valueFlagOptions(cmds, c)
+		if len(opts) == 0 {
+			continue
+		}
+
+		var flags []string
+		for _, o := range opts {
+			flags = append(flags, "-"+o.name, "--"+o.name)
+			if o.short != "" {
+				flags = append(flags, "-"+o.short)
+			}
+		}
+
+		fmt.Fprintf(w, "        case %s\n", fishQuote(c.name))
+		fmt.Fprintln(w, `            switch $argv[2]`)
+		fmt.Fprintf(w, "                case %s\n", strings.Join(fishQuoteSlice(flags), " "))
+		fmt.Fprintln(w, `                    return 0`)
+		fmt.Fprintln(w, `            end`)
+	}
+	fmt.Fprintln(w, `    end`)
+	fmt.Fprintln(w, `    return 1`)
+	fmt.Fprintln(w, `end`)
+	fmt.Fprintln(w)
+}
+
+type subcommandPattern struct {
+	name  string
+	words []string
+}
+
+// subcommandPatterns returns the subcommands of the given command with the
+// words that resolve them: their name followed by their aliases.
+func subcommandPatterns(c completionCommand) []subcommandPattern {
+	subs := make([]subcommandPattern, 0, len(c.subcommands))
+	for _, name := range c.subcommands {
+		words := []string{name}
+		for alias, sub := range c.aliases {
+			if sub == name {
+				words = append(words, alias)
+			}
+		}
+		sort.Strings(words[1:])
+
+		subs = append(subs, subcommandPattern{name: name, words: words})
+	}
+	return subs
+}
+
+// valueFlagOptions returns the options that take a value when parsed under the
+// given command: the non-boolean options of the command and, when undefined
+// there, the ones of the commands under it.
+func valueFlagOptions(cmds []completionCommand, c completionCommand) []completionOption {
+	var res []completionOption
+	seen := make(map[string]bool)
+
+	for _, sub := range cmds {
+		if sub.name != c.name && !(c.name == "" || strings.HasPrefix(sub.name, c.name+" ")) {
+			continue
+		}
+		// The command itself is sorted before the commands under it.
+		for _, o := range sub.options {
+			if seen[o.name] {
+				continue
+			}
+			seen[o.name] = true
+
+			if !o.isBool {
+				res = append(res, o)
+			}
+		}
+	}
+	return res
+}
+
+func valueOptions(opts []completionOption) []completionOption {
+	var res []completionOption
+	for _, o := range opts {
+		if !o.isBool {
+			res = append(res, o)
+		}
+	}
+	return res
+}
+
+// optionPatterns returns the shell case patterns that match the given option
+// flags.
+func optionPatterns(o completionOption) string {
+	patterns := shellQuote("-"+o.name) + "|" + shellQuote("--"+o.name)
+	if o.short != "" {
+		patterns += "|" + shellQuote("-"+o.short)
+	}
+	return patterns
+}
+
+func optionNames(opts []completionOption) []string {
+	names := make([]string, 0, len(opts))
+	for _, o := range opts {
+		names = append(names, "--"+o.name)
+	}
+	return names
+}
+
+func completionFuncName(programName string) string {
+	return "__" + funcNameReplacer.ReplaceAllString(programName, "_") + "_completion"
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func shellQuoteAll(s []string) string {
+	return strings.Join(shellQuoteSlice(s), " ")
+}
+
+func shellQuoteSlice(s []string) []string {
+	quoted := make([]string, 0, len(s))
+	for _, v := range s {
+		quoted = append(quoted, shellQuote(v))
+	}
+	return quoted
+}
+
+func fishQuoteSlice(s []string) []string {
+	quoted := make([]string, 0, len(s))
+	for _, v := range s {
+		quoted = append(quoted, fishQuote(v))
+	}
+	return quoted
+}
+
+func fishQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+}
+
+func containsString(s []string, v string) bool {
+	for _, e := range s {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,230 @@
+package cli
+
+import (
+	"bytes"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func completionTestManager() commandManager {
+	opts := struct {
+		Verbose bool `short:"v"`
+		Metrics struct {
+			PathFormatter string
+		}
+		Secret string `cli:"secret,hidden"`
+		Level  string `short:"l" validate:"oneof=debug|info"`
+	}{}
+
+	m := commandManager{}
+	m.register().Help("Root command.").Options(&opts)
+	m.register("foo", "bar").Help("Foo bar command.").Options(&opts)
+	m.register("foo", "buu").Help("Foo buu command.")
+	return m
+}
+
+func TestCompletionCommands(t *testing.T) {
+	m := completionTestManager()
+
+	cmds, err := completionCommands(m.commands)
+	require.NoError(t, err)
+	require.Len(t, cmds, 4)
+
+	require.Equal(t, "", cmds[0].name)
+	require.Equal(t, []string{"foo"}, cmds[0].subcommands)
+	require.Equal(t, []string{
+		"--verbose",
+		"--metrics",
+		"--metrics.path-formatter",
+		"--level",
+		"--config",
+		"--help",
+	}, optionNames(cmds[0].options))
+
+	require.Equal(t, "foo", cmds[1].name)
+	require.Equal(t, []string{"bar", "buu"}, cmds[1].subcommands)
+	require.Empty(t, cmds[1].options)
+
+	require.Equal(t, "foo bar", cmds[2].name)
+	require.Empty(t, cmds[2].subcommands)
+	require.True(t, cmds[2].options[0].isBool)
+	require.False(t, cmds[2].options[2].isBool)
+	require.Equal(t, []string{"debug", "info"}, cmds[2].options[3].values)
+
+	require.Equal(t, "foo buu", cmds[3].name)
+	require.Equal(t, []string{"--help"}, optionNames(cmds[3].options))
+}
+
+func TestCompletion(t *testing.T) {
+	tests := []struct {
+		shell    string
+		contains []string
+	}{
+		{
+			shell: Bash,
+			contains: []string{
+				"'foo')",
+				"subcommands='bar buu'",
+				"'-metrics.path-formatter'|'--metrics.path-formatter')",
+				"'-level'|'--level'|'-l')",
+				"COMPREPLY=($(compgen -W 'debug info' -- \"$cur\"))",
+				"complete -o default -F",
+			},
+		},
+		{
+			shell: Zsh,
+			contains: []string{
+				"#compdef",
+				"'foo bar')",
+				"options=('--verbose'",
+				"compadd -- 'debug' 'info'",
+				"compdef",
+			},
+		},
+		{
+			shell: Fish,
+			contains: []string{
+				"-a 'buu'",
+				"-l 'metrics.path-formatter' -r -F",
+				"-l 'verbose'",
+				"-l 'level' -s 'l' -x -a 'debug info'",
+				"-l 'verbose' -s 'v'",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.shell, func(t *testing.T) {
+			m := completionTestManager()
+
+			var w bytes.Buffer
+			err := m.completion(&w, test.shell)
+			require.NoError(t, err)
+			require.NotContains(t, w.String(), "secret")
+
+			for _, s := range test.contains {
+				require.Contains(t, w.String(), s)
+			}
+
+			if path, err := exec.LookPath(test.shell); err == nil {
+				out, err := exec.Command(path, "-n", "-c", w.String()).CombinedOutput()
+				require.NoError(t, err, string(out))
+			}
+		})
+	}
+}
+
+func TestBashCompletionCommandPath(t *testing.T) {
+	path, err := exec.LookPath(Bash)
+	if err != nil {
+		t.Skip("bash is not installed")
+	}
+
+	rootOpts := struct {
+		Level   string `short:"l"`
+		Verbose bool
+	}{}
+	migrateOpts := struct {
+		Steps int
+	}{}
+	inspectOpts := struct {
+		File   string `arg:"0"`
+		Format string
+	}{}
+
+	m := commandManager{name: "test"}
+	m.register().PersistentOptions(&rootOpts)
+	m.register("migrate").Aliases("m").Options(&migrateOpts)
+	m.register("migrate", "up")
+	m.register("migrate", "down")
+	m.register("inspect").Options(&inspectOpts)
+	m.register("inspect", "all")
+
+	var script bytes.Buffer
+	err = m.completion(&script, Bash)
+	require.NoError(t, err)
+
+	tests := []struct {
+		scenario string
+		words    string
+		expected string
+	}{
+		{
+			scenario: "root subcommands",
+			words:    "test ''",
+			expected: "inspect migrate",
+		},
+		{
+			scenario: "flag with value before the command",
+			words:    "test --level a migrate ''",
+			expected: "down up",
+		},
+		{
+			scenario: "short flag with value before the command",
+			words:    "test -l a m ''",
+			expected: "down up",
+		},
+		{
+			scenario: "boolean flag before the command",
+			words:    "test --verbose migrate ''",
+			expected: "down up",
+		},
+		{
+			scenario: "flag with value split by bash",
+			words:    "test --level = a migrate ''",
+			expected: "down up",
+		},
+		{
+			scenario: "positional argument ends the command path",
+			words:    "test inspect file ''",
+			expected: "--format --level --verbose --config --help",
+		},
+		{
+			scenario: "double dash ends the command path",
+			words:    "test migrate -- ''",
+			expected: "--steps --level --verbose --config --help",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			cmd := script.String() + "\n" +
+				"COMP_WORDS=(" + test.words + ")\n" +
+				"COMP_CWORD=$((${#COMP_WORDS[@]} - 1))\n" +
+				"__test_completion\n" +
+				"echo \"${COMPREPLY[*]}\"\n"
+
+			out, err := exec.Command(path, "-c", cmd).CombinedOutput()
+			require.NoError(t, err, string(out))
+			require.Equal(t, test.expected+"\n", string(out))
+		})
+	}
+}
+
+func TestCompletionUnsupportedShell(t *testing.T) {
+	m := completionTestManager()
+	err := m.completion(&bytes.Buffer{}, "powershell")
+	require.Error(t, err)
+	t.Log("error:", err)
+}
+
+func TestRegisterCompletion(t *testing.T) {
+	var stdout bytes.Buffer
+	var exitCode int
+
+	app := New("test")
+	app.Args = []string{"completion", "zsh"}
+	app.Stdout = &stdout
+	app.Exit = func(code int) {
+		exitCode = code
+	}
+
+	app.RegisterCompletion()
+	require.Len(t, app.manager.commands, 3)
+	require.Equal(t, NoCommand, app.Load())
+	require.Equal(t, "completion zsh", app.LoadedCommand())
+	require.Zero(t, exitCode)
+	require.Contains(t, stdout.String(), "compdef __test_completion test")
+}
@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	configOptionName = "config"
+)
+
+func loadConfigFile(filename string, opts []option) error {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("reading config file failed: %w", err)
+	}
+
+	config := make(map[string]interface{})
+
+	switch ext := strings.ToLower(filepath.Ext(filename)); ext {
+	case ".json":
+		err = json.Unmarshal(b, &config)
+
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &config)
+
+	case ".toml":
+		err = toml.Unmarshal(b, &config)
+
+	default:
+		return fmt.Errorf("unsupported config file extension: %q", ext)
+	}
+	if err != nil {
+		return fmt.Errorf("decoding config file %q failed: %w", filename, err)
+	}
+
+	for _, o := range opts {
+		if o.configPath == nil {
+			continue
+		}
+
+		v, ok := lookupConfigValue(config, o.configPath)
+		if !ok {
+			continue
+		}
+
+		// Nested structs are set by their own fields.
+		if _, isMap := v.(map[string]interface{}); isMap && o.value.Kind() == reflect.Struct {
+			continue
+		}
+
+		if err := o.setConfigValue(v); err != nil {
+			return fmt.Errorf("setting %q from config file failed: %w", strings.Join(o.configPath, "."), err)
+		}
+		o.setSource(sourceConfig, filename)
+	}
+
+	return nil
+}
+
+func lookupConfigValue(config map[string]interface{}, path []string) (interface{}, bool) {
+	var v interface{} = config
+
+	for _, k := range path {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+
+		if v, ok = lookupConfigKey(m, k); !ok {
+			return nil, false
+		}
+	}
+
+	return v, true
+}
+
+func lookupConfigKey(m map[string]interface{}, k string) (interface{}, bool) {
+	if v, ok := m[k]; ok {
+		return v, true
+	}
+
+	k = normalizeCLIOptionName(k)
+	for key, v := range m {
+		if normalizeCLIOptionName(key) == k {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func (o option) setConfigValue(v interface{}) error {
+	if s, ok := v.(string); ok {
+		return o.Set(s)
+	}
+
+	switch v := v.(type) {
+	case map[string]interface{}, []interface{}:
+
+	case encoding.TextMarshaler:
+		if o.isText() {
+			b, err := v.MarshalText()
+			if err != nil {
+				return err
+			}
+			return o.Set(string(b))
+		}
+
+	default:
+		if o.isText() {
+			return o.Set(fmt.Sprint(v))
+		}
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	if o.value.Kind() == reflect.String {
+		o.value.SetString(string(b))
+		return nil
+	}
+	return json.Unmarshal(b, o.value.Addr().Interface())
+}
+
+// lookupFlagValue returns the value of the named flag from the given args. It
+// stops looking at the first "--" argument.
+func lookupFlagValue(args []string, name string) (string, bool) {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+
+		arg = strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+		if arg == name && i+1 < len(args) {
+			return args[i+1], true
+		}
+
+		if value, ok := strings.CutPrefix(arg, name+"="); ok {
+			return value, true
+		}
+	}
+
+	return "", false
+}
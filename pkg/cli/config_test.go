@@ -0,0 +1,267 @@
+package cli
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aukilabs/go-tooling/pkg/logs"
+	"github.com/stretchr/testify/require"
+)
+
+type configOptions struct {
+	Int      int
+	String   string `config:"str"`
+	Duration time.Duration
+	Ignored  string `config:"-"`
+	Slice    []string
+	Nested   struct {
+		PathFormatter string
+		Float         float64
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	tests := []struct {
+		scenario string
+		filename string
+		content  string
+		err      bool
+	}{
+		{
+			scenario: "json file",
+			filename: "config.json",
+			content: `{
+				"int": 21,
+				"str": "bar",
+				"duration": "1m",
+				"ignored": "ignored",
+				"slice": ["foo", "bar"],
+				"nested": {
+					"pathFormatter": "path",
+					"float": 42.1
+				}
+			}`,
+		},
+		{
+			scenario: "yaml file",
+			filename: "config.yaml",
+			content: `
+int: 21
+str: bar
+duration: 1m
+ignored: ignored
+slice:
+  - foo
+  - bar
+nested:
+  path-formatter: path
+  float: 42.1
+`,
+		},
+		{
+			scenario: "toml file",
+			filename: "config.toml",
+			content: `
+int = 21
+str = "bar"
+duration = "1m"
+ignored = "ignored"
+slice = ["foo", "bar"]
+
+[nested]
+path_formatter = "path"
+float = 42.1
+`,
+		},
+		{
+			scenario: "unsupported extension returns an error",
+			filename: "config.ini",
+			content:  "int=21",
+			err:      true,
+		},
+		{
+			scenario: "malformed file returns an error",
+			filename: "config.json",
+			content:  "{",
+			err:      true,
+		},
+		{
+			scenario: "invalid value returns an error",
+			filename: "config.json",
+			content:  `{"int": "foo"}`,
+			err:      true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), test.filename)
+			err := os.WriteFile(filename, []byte(test.content), 0666)
+			require.NoError(t, err)
+
+			var opts configOptions
+			p := optionParser{
+				flags:      flag.NewFlagSet("test", flag.ContinueOnError),
+				configFile: filename,
+			}
+
+			_, err = p.parse(&opts)
+			if test.err {
+				require.Error(t, err)
+				t.Log("error:", err)
+				return
+			}
+			require.NoError(t, err)
+
+			require.Equal(t, 21, opts.Int)
+			require.Equal(t, "bar", opts.String)
+			require.Equal(t, time.Minute, opts.Duration)
+			require.Empty(t, opts.Ignored)
+			require.Equal(t, []string{"foo", "bar"}, opts.Slice)
+			require.Equal(t, "path", opts.Nested.PathFormatter)
+			require.Equal(t, 42.1, opts.Nested.Float)
+		})
+	}
+}
+
+func TestLoadConfigFileTextValues(t *testing.T) {
+	tests := []struct {
+		scenario string
+		filename string
+		content  string
+	}{
+		{
+			scenario: "json string",
+			filename: "config.json",
+			content:  `{"start": "2024-01-02T03:04:05Z", "level": "warning"}`,
+		},
+		{
+			scenario: "yaml timestamp",
+			filename: "config.yaml",
+			content:  "start: 2024-01-02T03:04:05Z\nlevel: warning\n",
+		},
+		{
+			scenario: "toml datetime",
+			filename: "config.toml",
+			content:  "start = 2024-01-02T03:04:05Z\nlevel = \"warning\"\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), test.filename)
+			err := os.WriteFile(filename, []byte(test.content), 0666)
+			require.NoError(t, err)
+
+			var opts struct {
+				Start time.Time
+				Level logs.Level
+			}
+			p := optionParser{
+				flags:      flag.NewFlagSet("test", flag.ContinueOnError),
+				configFile: filename,
+			}
+
+			_, err = p.parse(&opts)
+			require.NoError(t, err)
+			require.True(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC).Equal(opts.Start))
+			require.Equal(t, logs.WarningLevel, opts.Level)
+		})
+	}
+}
+
+func TestLoadConfigFileNotFound(t *testing.T) {
+	var opts configOptions
+	p := optionParser{
+		flags:      flag.NewFlagSet("test", flag.ContinueOnError),
+		configFile: filepath.Join(t.TempDir(), "config.json"),
+	}
+
+	_, err := p.parse(&opts)
+	require.Error(t, err)
+	t.Log("error:", err)
+}
+
+func TestConfigFilePrecedence(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(filename, []byte(`{"int": 21, "str": "bar", "duration": "1m"}`), 0666)
+	require.NoError(t, err)
+
+	os.Setenv("STRING", "env")
+	defer os.Unsetenv("STRING")
+
+	opts := configOptions{Int: 42, Duration: time.Second}
+
+	m := commandManager{}
+	m.register().Options(&opts)
+
+	_, _, err = m.parse("-config", filename, "-int", "84")
+	require.NoError(t, err)
+	require.Equal(t, 84, opts.Int)
+	require.Equal(t, "env", opts.String)
+	require.Equal(t, time.Minute, opts.Duration)
+}
+
+func TestConfigOptionOverride(t *testing.T) {
+	opts := struct {
+		Config string
+	}{}
+
+	m := commandManager{}
+	m.register().Options(&opts)
+
+	_, _, err := m.parse("-config", "config.json")
+	require.NoError(t, err)
+	require.Equal(t, "config.json", opts.Config)
+}
+
+func TestLookupFlagValue(t *testing.T) {
+	tests := []struct {
+		scenario string
+		args     []string
+		value    string
+		found    bool
+	}{
+		{
+			scenario: "single dash flag",
+			args:     []string{"-int", "42", "-config", "config.json"},
+			value:    "config.json",
+			found:    true,
+		},
+		{
+			scenario: "double dash flag",
+			args:     []string{"--config", "config.json", "-int", "42"},
+			value:    "config.json",
+			found:    true,
+		},
+		{
+			scenario: "flag with equal sign",
+			args:     []string{"--config=config.json"},
+			value:    "config.json",
+			found:    true,
+		},
+		{
+			scenario: "flag without value",
+			args:     []string{"--config"},
+		},
+		{
+			scenario: "flag after end of options",
+			args:     []string{"--", "--config", "config.json"},
+		},
+		{
+			scenario: "missing flag",
+			args:     []string{"--configuration", "config.json"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			value, found := lookupFlagValue(test.args, "config")
+			require.Equal(t, test.value, value)
+			require.Equal(t, test.found, found)
+		})
+	}
+}
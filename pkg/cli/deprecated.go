@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aukilabs/go-tooling/pkg/logs"
+)
+
+// parseDeprecatedTag returns the deprecated environment variables and flag
+// names from the given deprecated tag. Names that start with a dash are flags.
+// eg. `deprecated:"OLD_ENV,--old-flag"`.
+func parseDeprecatedTag(tag string) (envKeys, flags []string, err error) {
+	if tag == "" {
+		return nil, nil, nil
+	}
+
+	for _, name := range strings.Split(tag, ",") {
+		name = strings.TrimSpace(name)
+
+		switch {
+		case name == "" || name == "-" || name == "--":
+			return nil, nil, fmt.Errorf("invalid deprecated name: %q", name)
+
+		case strings.HasPrefix(name, "-"):
+			flags = append(flags, strings.TrimPrefix(name[1:], "-"))
+
+		default:
+			envKeys = append(envKeys, name)
+		}
+	}
+	return envKeys, flags, nil
+}
+
+// checkDeprecated ensures that deprecated flag names do not collide with the
+// names and short aliases of other options.
+func checkDeprecated(opts []option) error {
+	names := make(map[string]string, len(opts))
+	for _, o := range opts {
+		names[o.name] = o.name
+		if o.short != "" {
+			names[o.short] = o.name
+		}
+	}
+
+	for _, o := range opts {
+		if o.isArg && (len(o.deprecatedEnvKeys) != 0 || len(o.deprecatedFlags) != 0) {
+			return fmt.Errorf("argument %q cannot have deprecated names", o.name)
+		}
+
+		for _, f := range o.deprecatedFlags {
+			if name, ok := names[f]; ok {
+				return fmt.Errorf("option %q deprecated flag %q collides with option %q", o.name, f, name)
+			}
+			names[f] = o.name
+		}
+	}
+	return nil
+}
+
+// loadDeprecatedEnv sets the option with the value of the first deprecated
+// environment variable found in the given source, and logs a warning to
+// migrate to the current one.
+func (o option) loadDeprecatedEnv(s Source) error {
+	for _, k := range o.deprecatedEnvKeys {
+		v, ok, err := s.Lookup("", k)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+
+		logs.WithTag("option", o.name).
+			Warnf("environment variable %s is deprecated, use %s instead", k, o.envKey)
+
+		return o.setSourceValue(v)
+	}
+	return nil
+}
+
+// deprecatedFlagValue is the value of a deprecated flag name. It sets the
+// option of the current flag and logs a warning to migrate to it.
+type deprecatedFlagValue struct {
+	*flagValue
+	name string
+}
+
+func (f deprecatedFlagValue) Set(s string) error {
+	logs.WithTag("option", f.option.name).
+		Warnf("flag %s is deprecated, use %s instead", flagName(f.name), flagName(f.option.name))
+
+	if err := f.flagValue.set(flagName(f.name), s); err != nil {
+		return err
+	}
+
+	f.setSource(sourceFlag, flagName(f.name))
+	return nil
+}
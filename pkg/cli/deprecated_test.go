@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/aukilabs/go-tooling/pkg/logs"
+	"github.com/stretchr/testify/require"
+)
+
+type deprecatedOptions struct {
+	Endpoint string `env:"API_ENDPOINT" deprecated:"API_URL,--url"`
+	Hosts    []string
+	Verbose  bool `deprecated:"--debug,-d"`
+}
+
+func TestParseDeprecatedTag(t *testing.T) {
+	tests := []struct {
+		scenario        string
+		tag             string
+		expectedEnvKeys []string
+		expectedFlags   []string
+		err             bool
+	}{
+		{
+			scenario: "empty tag",
+		},
+		{
+			scenario:        "env keys and flags",
+			tag:             "OLD_ENV, --old-flag,-o",
+			expectedEnvKeys: []string{"OLD_ENV"},
+			expectedFlags:   []string{"old-flag", "o"},
+		},
+		{
+			scenario: "empty name returns an error",
+			tag:      "OLD_ENV,",
+			err:      true,
+		},
+		{
+			scenario: "dash only returns an error",
+			tag:      "--",
+			err:      true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			envKeys, flags, err := parseDeprecatedTag(test.tag)
+			if test.err {
+				require.Error(t, err)
+				t.Log("error:", err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expectedEnvKeys, envKeys)
+			require.Equal(t, test.expectedFlags, flags)
+		})
+	}
+}
+
+func TestDeprecatedOptions(t *testing.T) {
+	tests := []struct {
+		scenario         string
+		env              map[string]string
+		args             []string
+		expected         deprecatedOptions
+		expectedSource   string
+		expectedWarnings int
+	}{
+		{
+			scenario:         "deprecated env",
+			env:              map[string]string{"API_URL": "http://old"},
+			expected:         deprecatedOptions{Endpoint: "http://old"},
+			expectedSource:   "env:API_URL",
+			expectedWarnings: 1,
+		},
+		{
+			scenario: "current env overrides deprecated env",
+			env: map[string]string{
+				"API_URL":      "http://old",
+				"API_ENDPOINT": "http://new",
+			},
+			expected:         deprecatedOptions{Endpoint: "http://new"},
+			expectedSource:   "env:API_ENDPOINT",
+			expectedWarnings: 1,
+		},
+		{
+			scenario:         "deprecated flag",
+			args:             []string{"--url", "http://old", "-d"},
+			expected:         deprecatedOptions{Endpoint: "http://old", Verbose: true},
+			expectedSource:   "flag:--url",
+			expectedWarnings: 2,
+		},
+		{
+			scenario:       "current flag",
+			args:           []string{"--endpoint", "http://new"},
+			expected:       deprecatedOptions{Endpoint: "http://new"},
+			expectedSource: "flag:--endpoint",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			var warnings []string
+			logs.SetLogger(func(e logs.Entry) {
+				if e.Level() == logs.WarningLevel {
+					warnings = append(warnings, fmt.Sprint(e))
+				}
+			})
+			defer logs.SetLogger(func(e logs.Entry) { fmt.Println(e) })
+
+			var opts deprecatedOptions
+
+			m := commandManager{
+				out: writerNoop{},
+				lookupEnv: func(k string) (string, bool) {
+					v, ok := test.env[k]
+					return v, ok
+				},
+			}
+			m.register().Options(&opts)
+
+			_, _, err := m.parse(test.args...)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, opts)
+			require.Equal(t, test.expectedSource, m.options[0].sourceString())
+			require.Len(t, warnings, test.expectedWarnings)
+			t.Log(warnings)
+		})
+	}
+}
+
+func TestDeprecatedOptionsUsage(t *testing.T) {
+	var p optionParser
+	err := p.collect(&deprecatedOptions{})
+	require.NoError(t, err)
+
+	w := bytes.NewBuffer(nil)
+	commandUsage(w, defaultUsageWidth, "test", &command{}, p.options, nil)()
+	require.NotContains(t, w.String(), "API_URL")
+	require.NotContains(t, w.String(), "--url")
+	require.NotContains(t, w.String(), "--debug")
+	t.Log(w.String())
+}
+
+func TestCheckDeprecated(t *testing.T) {
+	tests := []struct {
+		scenario string
+		options  interface{}
+	}{
+		{
+			scenario: "flag collides with an option",
+			options: &struct {
+				Host string `deprecated:"--port"`
+				Port int
+			}{},
+		},
+		{
+			scenario: "flag collides with a short alias",
+			options: &struct {
+				Host    string `deprecated:"-v"`
+				Verbose bool   `short:"v"`
+			}{},
+		},
+		{
+			scenario: "argument",
+			options: &struct {
+				File string `arg:"0" deprecated:"FILE"`
+			}{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			var p optionParser
+			err := p.collect(test.options)
+			require.Error(t, err)
+			t.Log("error:", err)
+		})
+	}
+}
@@ -0,0 +1,376 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Supported documentation formats.
+const (
+	Markdown = "markdown"
+	Man      = "man"
+)
+
+var (
+	markdownCellReplacer = strings.NewReplacer("|", `\|`, "\n", " ")
+	roffReplacer         = strings.NewReplacer(`\`, `\e`, "-", `\-`)
+)
+
+// GenerateDocs writes the reference documentation of the commands registered
+// in the default app in the given directory. See App.GenerateDocs.
+func GenerateDocs(dir, format string) error {
+	return defaultApp.GenerateDocs(dir, format)
+}
+
+// RegisterDocs registers the docs command in the default app. See
+// App.RegisterDocs.
+func RegisterDocs() {
+	defaultApp.RegisterDocs()
+}
+
+// GenerateDocs writes one reference page per registered command in the given
+// directory. Supported formats are markdown and man. Hidden commands and
+// options are not documented.
+func (a *App) GenerateDocs(dir, format string) error {
+	return a.commandManager().docs(dir, format)
+}
+
+// RegisterDocs registers the hidden "docs" command that generates the
+// reference documentation of the registered commands when loaded.
+func (a *App) RegisterDocs() {
+	opts := docsOptions{
+		Dir:    "docs",
+		Format: Markdown,
+	}
+
+	c := a.Register("docs").
+		Help("Generates the reference documentation of the program commands.").
+		Options(&opts).
+		Hidden().(*command)
+	c.builtin = func() error {
+		return a.GenerateDocs(opts.Dir, opts.Format)
+	}
+}
+
+type docsOptions struct {
+	Dir    string `env:"-" help:"The directory where documentation pages are written."`
+	Format string `env:"-" validate:"oneof=markdown|man" help:"The documentation format."`
+}
+
+type docsCommand struct {
+	command     *command
+	name        string
+	help        string
+	options     []option
+	subcommands []*command
+}
+
+func (m *commandManager) docs(dir, format string) error {
+	var write func(io.Writer, string, docsCommand)
+	var ext string
+
+	switch format {
+	case Markdown:
+		write = writeMarkdownPage
+		ext = ".md"
+
+	case Man:
+		write = writeManPage
+		ext = ".1"
+
+	default:
+		return fmt.Errorf("unsupported documentation format: %q", format)
+	}
+
+	cmds, err := docsCommands(m.commands)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating documentation directory failed: %w", err)
+	}
+
+	programName := m.programName()
+	for _, c := range cmds {
+		var b bytes.Buffer
+		write(&b, programName, c)
+
+		filename := filepath.Join(dir, docsPageName(programName, c.name)+ext)
+		if err := os.WriteFile(filename, b.Bytes(), 0644); err != nil {
+			return fmt.Errorf("writing %q documentation failed: %w", commandString(programName, c.name), err)
+		}
+	}
+	return nil
+}
+
+// docsCommands returns the documented commands sorted by name. The root page
+// is always returned since it is the entry point of the documentation.
+// Subcommands are the closest registered descendants of a command.
+func docsCommands(cmds map[string]Command) ([]docsCommand, error) {
+	visible := visibleCommands(cmds)
+
+	nodes := map[string]*docsCommand{"": {}}
+	for name, c := range visible {
+		p := optionParser{persistent: persistentOptions(cmds, name)}
+		if err := p.collect(c.options); err != nil {
+			return nil, fmt.Errorf("parsing %q options failed: %w", name, err)
+		}
+
+		n, ok := nodes[name]
+		if !ok {
+			n = &docsCommand{}
+			nodes[name] = n
+		}
+		n.command = c
+		n.name = name
+		n.help = c.help
+		n.options = p.options
+
+		if name == "" {
+			continue
+		}
+
+		pname := parentCommandName(visible, name)
+		pnode, ok := nodes[pname]
+		if !ok {
+			pnode = &docsCommand{name: pname}
+			nodes[pname] = pnode
+		}
+		pnode.subcommands = append(pnode.subcommands, c)
+	}
+
+	res := make([]docsCommand, 0, len(nodes))
+	for _, n := range nodes {
+		sort.Slice(n.subcommands, func(i, j int) bool {
+			return n.subcommands[i].name < n.subcommands[j].name
+		})
+		res = append(res, *n)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].name < res[j].name
+	})
+	return res, nil
+}
+
+func docsPageName(programName, cmd string) string {
+	return strings.Join(strings.Fields(commandString(programName, cmd)), "-")
+}
+
+func docsUsage(programName string, c docsCommand) string {
+	usage := commandString(programName, c.name)
+	if len(c.options) != len(commandArgs(c.options)) {
+		usage += " [options]"
+	}
+	if args := argsUsage(c.options); args != "" {
+		usage += " " + args
+	}
+	if len(c.subcommands) != 0 {
+		usage += " <command>"
+	}
+	return usage
+}
+
+func writeMarkdownPage(w io.Writer, programName string, c docsCommand) {
+	fmt.Fprintf(w, "# %s\n\n", commandString(programName, c.name))
+
+	if c.help != "" {
+		fmt.Fprintf(w, "%s\n\n", c.help)
+	}
+
+	if c.command != nil && c.command.deprecated != "" {
+		fmt.Fprintf(w, "**Deprecated:** %s\n\n", c.command.deprecated)
+	}
+
+	fmt.Fprintf(w, "## Usage\n\n```\n%s\n```\n", docsUsage(programName, c))
+
+	if args := commandArgs(c.options); len(args) != 0 {
+		fmt.Fprint(w, "\n## Arguments\n\n")
+		fmt.Fprintln(w, "| Name | Type | Description |")
+		fmt.Fprintln(w, "| ---- | ---- | ----------- |")
+		for _, a := range args {
+			fmt.Fprintf(w, "| `%s` | `%s` | %s |\n",
+				argUsage(a),
+				optionTypeName(a),
+				markdownCell(a.help),
+			)
+		}
+	}
+
+	if opts := documentedOptions(c.options, false); len(opts) != 0 {
+		fmt.Fprint(w, "\n## Options\n\n")
+		writeMarkdownOptions(w, opts)
+	}
+
+	if opts := documentedOptions(c.options, true); len(opts) != 0 {
+		fmt.Fprint(w, "\n## Global options\n\n")
+		writeMarkdownOptions(w, opts)
+	}
+
+	if len(c.subcommands) != 0 {
+		fmt.Fprint(w, "\n## Commands\n\n")
+		fmt.Fprintln(w, "| Command | Description |")
+		fmt.Fprintln(w, "| ------- | ----------- |")
+		for _, sub := range c.subcommands {
+			fmt.Fprintf(w, "| [%s](%s.md) | %s |\n",
+				commandString(programName, sub.name),
+				docsPageName(programName, sub.name),
+				markdownCell(sub.help),
+			)
+		}
+	}
+}
+
+func writeManPage(w io.Writer, programName string, c docsCommand) {
+	title := strings.ToUpper(docsPageName(programName, c.name))
+	fmt.Fprintf(w, ".TH \"%s\" \"1\" \"\" \"%s\" \"%s Manual\"\n", roff(title), roff(programName), roff(programName))
+
+	fmt.Fprintln(w, ".SH NAME")
+	name := roff(docsPageName(programName, c.name))
+	if summary, _, _ := strings.Cut(strings.TrimSpace(c.help), "\n"); summary != "" {
+		fmt.Fprintf(w, "%s \\- %s\n", name, roff(summary))
+	} else {
+		fmt.Fprintln(w, name)
+	}
+
+	fmt.Fprintln(w, ".SH SYNOPSIS")
+	fmt.Fprintln(w, roffLine(docsUsage(programName, c)))
+
+	if c.help != "" {
+		fmt.Fprintln(w, ".SH DESCRIPTION")
+		fmt.Fprintln(w, roffLine(c.help))
+	}
+
+	if c.command != nil && c.command.deprecated != "" {
+		fmt.Fprintln(w, ".SH DEPRECATED")
+		fmt.Fprintln(w, roffLine(c.command.deprecated))
+	}
+
+	if args := commandArgs(c.options); len(args) != 0 {
+		fmt.Fprintln(w, ".SH ARGUMENTS")
+		for _, a := range args {
+			fmt.Fprintln(w, ".TP")
+			fmt.Fprintf(w, "\\fB%s\\fR \\fI%s\\fR\n", roff(argUsage(a)), roff(optionTypeName(a)))
+			if a.help != "" {
+				fmt.Fprintln(w, roffLine(a.help))
+			}
+		}
+	}
+
+	if opts := documentedOptions(c.options, false); len(opts) != 0 {
+		fmt.Fprintln(w, ".SH OPTIONS")
+		writeManOptions(w, opts)
+	}
+
+	if opts := documentedOptions(c.options, true); len(opts) != 0 {
+		fmt.Fprintln(w, ".SH GLOBAL OPTIONS")
+		writeManOptions(w, opts)
+	}
+
+	if len(c.subcommands) != 0 {
+		fmt.Fprintln(w, ".SH COMMANDS")
+		for _, sub := range c.subcommands {
+			fmt.Fprintln(w, ".TP")
+			fmt.Fprintf(w, "\\fB%s\\fR\n", roff(commandString(programName, sub.name)))
+			if sub.help != "" {
+				fmt.Fprintln(w, roffLine(sub.help))
+			}
+		}
+
+		fmt.Fprintln(w, ".SH SEE ALSO")
+		refs := make([]string, 0, len(c.subcommands))
+		for _, sub := range c.subcommands {
+			refs = append(refs, fmt.Sprintf("\\fB%s\\fR(1)", roff(docsPageName(programName, sub.name))))
+		}
+		fmt.Fprintln(w, strings.Join(refs, ", "))
+	}
+}
+
+func writeMarkdownOptions(w io.Writer, opts []option) {
+	var info optionFormatInfo
+
+	fmt.Fprintln(w, "| Name | Type | Env | Default | Description |")
+	fmt.Fprintln(w, "| ---- | ---- | --- | ------- | ----------- |")
+	for _, o := range opts {
+		fmt.Fprintf(w, "| `%s` | `%s` | %s | %s | %s |\n",
+			info.name(o),
+			optionTypeName(o),
+			markdownCode(optionEnvKey(o)),
+			markdownCode(optionDefault(o)),
+			markdownCell(optionHelp(o)),
+		)
+	}
+}
+
+func writeManOptions(w io.Writer, opts []option) {
+	var info optionFormatInfo
+
+	for _, o := range opts {
+		fmt.Fprintln(w, ".TP")
+		fmt.Fprintf(w, "\\fB%s\\fR \\fI%s\\fR\n", roff(info.name(o)), roff(optionTypeName(o)))
+		if help := optionHelp(o); help != "" {
+			fmt.Fprintln(w, roffLine(help))
+		}
+		if env := optionEnvKey(o); env != "" {
+			fmt.Fprintln(w, ".br")
+			fmt.Fprintf(w, "Env: %s\n", roff(env))
+		}
+		if def := optionDefault(o); def != "" {
+			fmt.Fprintln(w, ".br")
+			fmt.Fprintf(w, "Default: %s\n", roff(def))
+		}
+	}
+}
+
+// documentedOptions returns the visible options that are displayed in the
+// options section, or in the global options section when persistent is true.
+func documentedOptions(opts []option, persistent bool) []option {
+	res := make([]option, 0, len(opts))
+	for _, o := range opts {
+		if !o.isHidden && !o.isArg && o.isPersistent == persistent {
+			res = append(res, o)
+		}
+	}
+	return res
+}
+
+func optionEnvKey(o option) string {
+	if o.envKey == "-" {
+		return ""
+	}
+	return o.envKeys()
+}
+
+func markdownCell(s string) string {
+	return markdownCellReplacer.Replace(strings.TrimSpace(s))
+}
+
+func markdownCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + markdownCell(s) + "`"
+}
+
+func roff(s string) string {
+	return roffReplacer.Replace(s)
+}
+
+// roffLine returns the given text escaped as roff text lines. Lines starting
+// with a control character are escaped to not be interpreted as requests.
+func roffLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	for i, l := range lines {
+		l = roff(strings.TrimSpace(l))
+		if strings.HasPrefix(l, ".") || strings.HasPrefix(l, "'") {
+			l = `\&` + l
+		}
+		lines[i] = l
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateDocs(t *testing.T) {
+	tests := []struct {
+		scenario string
+		format   string
+		files    map[string][]string
+		err      bool
+	}{
+		{
+			scenario: "markdown",
+			format:   Markdown,
+			files: map[string][]string{
+				"test.md": {
+					"# test",
+					"A root command",
+					"| [test foo](test-foo.md) | A test command |",
+				},
+				"test-foo.md": {
+					"# test foo",
+					"test foo [options] [file] <command>",
+					"| [test foo bar baz](test-foo-bar-baz.md) | A nested command |",
+					"| `[file]` | `string` | The file to process. |",
+					"| `-f, --float` | `float64` | `FLOAT` | `42.42` | A float with a \\| pipe. |",
+					"| `--config` | `string` |  |  |",
+				},
+				"test-foo-bar-baz.md": {
+					"# test foo bar baz",
+				},
+			},
+		},
+		{
+			scenario: "man",
+			format:   Man,
+			files: map[string][]string{
+				"test.1": {
+					`.TH "TEST" "1" "" "test" "test Manual"`,
+					`test \- A root command`,
+					`\fBtest foo\fR`,
+					`\fBtest\-foo\fR(1)`,
+				},
+				"test-foo.1": {
+					`\fB[file]\fR \fIstring\fR`,
+					`\fB\-f, \-\-float\fR \fIfloat64\fR`,
+					"Env: FLOAT",
+					"Default: 42.42",
+					`\fBtest\-foo\-bar\-baz\fR(1)`,
+				},
+				"test-foo-bar-baz.1": {
+					`.TH "TEST\-FOO\-BAR\-BAZ"`,
+				},
+			},
+		},
+		{
+			scenario: "unsupported format returns an error",
+			format:   "html",
+			err:      true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			opts := struct {
+				File   string  `arg:"0" help:"The file to process."`
+				Float  float64 `short:"f" help:"A float with a | pipe."`
+				Hidden string  `cli:"hidden,hidden"`
+			}{
+				Float: 42.42,
+			}
+
+			app := New("test")
+			app.Register().Help("A root command")
+			app.Register("foo").Help("A test command").Options(&opts)
+			app.Register("foo", "bar", "baz").Help("A nested command")
+			app.RegisterDocs()
+
+			dir := t.TempDir()
+			err := app.GenerateDocs(dir, test.format)
+			if test.err {
+				require.Error(t, err)
+				t.Log("error:", err)
+				return
+			}
+			require.NoError(t, err)
+
+			entries, err := os.ReadDir(dir)
+			require.NoError(t, err)
+			require.Len(t, entries, len(test.files))
+
+			for filename, contents := range test.files {
+				b, err := os.ReadFile(filepath.Join(dir, filename))
+				require.NoError(t, err)
+				for _, c := range contents {
+					require.Contains(t, string(b), c)
+				}
+				require.NotContains(t, string(b), "hidden")
+				require.NotContains(t, string(b), "docs")
+				t.Log(string(b))
+			}
+		})
+	}
+}
+
+func TestRegisterDocs(t *testing.T) {
+	dir := t.TempDir()
+
+	var code int
+	app := New("test")
+	app.Args = []string{"docs", "-dir", dir, "-format", Man}
+	app.Stderr = writerNoop{}
+	app.Exit = func(c int) {
+		code = c
+	}
+
+	app.Register("foo").Help("A test command")
+	app.RegisterDocs()
+
+	require.Equal(t, NoCommand, app.Load())
+	require.Equal(t, "docs", app.LoadedCommand())
+	require.Zero(t, code)
+	require.FileExists(t, filepath.Join(dir, "test.1"))
+	require.FileExists(t, filepath.Join(dir, "test-foo.1"))
+}
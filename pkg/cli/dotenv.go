@@ -0,0 +1,277 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SetEnvFiles sets the dotenv files of the default app. See App.EnvFiles.
+func SetEnvFiles(filenames ...string) {
+	defaultApp.EnvFiles = filenames
+}
+
+// parseDotenv parses the given dotenv content and stores its variables in the
+// given map. Variables referenced in values with $VAR or ${VAR} are expanded
+// with the given lookup function.
+func parseDotenv(s string, vars map[string]string, lookup func(string) (string, bool)) error {
+	p := dotenvParser{
+		s:      s,
+		line:   1,
+		lookup: lookup,
+	}
+
+	for {
+		p.skipBlank()
+		if p.done() {
+			return nil
+		}
+
+		if p.peek() == '#' {
+			p.skipLine()
+			continue
+		}
+
+		k, v, err := p.parseVar()
+		if err != nil {
+			return fmt.Errorf("line %d: %w", p.line, err)
+		}
+		vars[k] = v
+	}
+}
+
+type dotenvParser struct {
+	s      string
+	i      int
+	line   int
+	lookup func(string) (string, bool)
+}
+
+func (p *dotenvParser) parseVar() (k, v string, err error) {
+	if rest, ok := strings.CutPrefix(p.s[p.i:], "export"); ok && rest != "" && isDotenvSpace(rest[0]) {
+		p.i += len("export")
+		p.skipSpaces()
+	}
+
+	start := p.i
+	for !p.done() && isDotenvKeyChar(p.peek()) {
+		p.i++
+	}
+	k = p.s[start:p.i]
+	if k == "" {
+		return "", "", fmt.Errorf("invalid variable name at %q", p.rest())
+	}
+
+	p.skipSpaces()
+	if p.done() || p.peek() != '=' {
+		return "", "", fmt.Errorf("missing = after %q", k)
+	}
+	p.i++
+	p.skipSpaces()
+
+	switch {
+	case p.done():
+
+	case p.peek() == '\'':
+		v, err = p.parseSingleQuoted()
+
+	case p.peek() == '"':
+		v, err = p.parseDoubleQuoted()
+
+	default:
+		v = p.parseUnquoted()
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	p.skipSpaces()
+	switch {
+	case p.done():
+
+	case p.peek() == '#':
+		p.skipLine()
+
+	case p.peek() == '\n' || p.peek() == '\r':
+
+	default:
+		return "", "", fmt.Errorf("unexpected characters after %q value: %q", k, p.rest())
+	}
+	return k, v, nil
+}
+
+// parseSingleQuoted returns a value that is taken literally.
+func (p *dotenvParser) parseSingleQuoted() (string, error) {
+	p.i++
+
+	end := strings.IndexByte(p.s[p.i:], '\'')
+	if end < 0 {
+		return "", errors.New("unterminated single quoted value")
+	}
+
+	v := p.s[p.i : p.i+end]
+	p.line += strings.Count(v, "\n")
+	p.i += end + 1
+	return v, nil
+}
+
+// parseDoubleQuoted returns a value where escape sequences and variables are
+// expanded.
+func (p *dotenvParser) parseDoubleQuoted() (string, error) {
+	p.i++
+
+	var b strings.Builder
+	for !p.done() {
+		c := p.peek()
+
+		switch c {
+		case '"':
+			p.i++
+			return b.String(), nil
+
+		case '\\':
+			p.i++
+			if p.done() {
+				break
+			}
+
+			switch e := p.peek(); e {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case '"', '\\', '$':
+				b.WriteByte(e)
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(e)
+			}
+			p.i++
+
+		case '$':
+			b.WriteString(p.expandVar())
+
+		default:
+			if c == '\n' {
+				p.line++
+			}
+			b.WriteByte(c)
+			p.i++
+		}
+	}
+
+	return "", errors.New("unterminated double quoted value")
+}
+
+// parseUnquoted returns a value that ends at the end of the line or at a
+// comment. Surrounding spaces are trimmed and variables are expanded.
+func (p *dotenvParser) parseUnquoted() string {
+	var b strings.Builder
+	for !p.done() {
+		c := p.peek()
+
+		switch {
+		case c == '\n' || c == '\r':
+			return strings.TrimSpace(b.String())
+
+		case c == '#' && p.i > 0 && isDotenvSpace(p.s[p.i-1]):
+			return strings.TrimSpace(b.String())
+
+		case c == '$':
+			b.WriteString(p.expandVar())
+
+		default:
+			b.WriteByte(c)
+			p.i++
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// expandVar returns the value of the $VAR or ${VAR} variable at the current
+// position. A dollar sign that is not followed by a variable name is returned
+// as it is.
+func (p *dotenvParser) expandVar() string {
+	p.i++
+
+	braced := !p.done() && p.peek() == '{'
+	if braced {
+		end := strings.IndexByte(p.s[p.i:], '}')
+		if end < 0 {
+			return "$"
+		}
+
+		name := p.s[p.i+1 : p.i+end]
+		p.i += end + 1
+		v, _ := p.lookup(name)
+		return v
+	}
+
+	start := p.i
+	for !p.done() && isDotenvKeyChar(p.peek()) && p.peek() != '.' {
+		p.i++
+	}
+	if start == p.i {
+		return "$"
+	}
+
+	v, _ := p.lookup(p.s[start:p.i])
+	return v
+}
+
+func (p *dotenvParser) skipBlank() {
+	for !p.done() {
+		switch c := p.peek(); {
+		case c == '\n':
+			p.line++
+			p.i++
+
+		case isDotenvSpace(c) || c == '\r':
+			p.i++
+
+		default:
+			return
+		}
+	}
+}
+
+func (p *dotenvParser) skipSpaces() {
+	for !p.done() && isDotenvSpace(p.peek()) {
+		p.i++
+	}
+}
+
+func (p *dotenvParser) skipLine() {
+	for !p.done() && p.peek() != '\n' {
+		p.i++
+	}
+}
+
+func (p *dotenvParser) rest() string {
+	end := strings.IndexByte(p.s[p.i:], '\n')
+	if end < 0 {
+		return p.s[p.i:]
+	}
+	return strings.TrimRight(p.s[p.i:p.i+end], "\r")
+}
+
+func (p *dotenvParser) peek() byte {
+	return p.s[p.i]
+}
+
+func (p *dotenvParser) done() bool {
+	return p.i >= len(p.s)
+}
+
+func isDotenvSpace(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+func isDotenvKeyChar(c byte) bool {
+	return c == '_' || c == '.' ||
+		c >= 'a' && c <= 'z' ||
+		c >= 'A' && c <= 'Z' ||
+		c >= '0' && c <= '9'
+}
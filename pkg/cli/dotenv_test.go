@@ -0,0 +1,228 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDotenv(t *testing.T) {
+	tests := []struct {
+		scenario string
+		content  string
+		expected map[string]string
+		err      bool
+	}{
+		{
+			scenario: "unquoted values",
+			content:  "HOST=localhost\nPORT = 8080 \n\nEMPTY=\n",
+			expected: map[string]string{
+				"HOST":  "localhost",
+				"PORT":  "8080",
+				"EMPTY": "",
+			},
+		},
+		{
+			scenario: "comments",
+			content:  "# Comment\nHOST=localhost # inline comment\nCOLOR=#fff\n  # indented comment\n",
+			expected: map[string]string{
+				"HOST":  "localhost",
+				"COLOR": "#fff",
+			},
+		},
+		{
+			scenario: "export prefix",
+			content:  "export HOST=localhost\nexport_DIR=/tmp\n",
+			expected: map[string]string{
+				"HOST":       "localhost",
+				"export_DIR": "/tmp",
+			},
+		},
+		{
+			scenario: "single quoted values are literal",
+			content:  `PASSWORD='p@ss # $HOST \n'`,
+			expected: map[string]string{
+				"PASSWORD": `p@ss # $HOST \n`,
+			},
+		},
+		{
+			scenario: "double quoted values",
+			content:  "GREETING=\"hello\\n\\\"world\\\" \\$HOST\" # comment\nMULTILINE=\"a\nb\"\n",
+			expected: map[string]string{
+				"GREETING":  "hello\n\"world\" $HOST",
+				"MULTILINE": "a\nb",
+			},
+		},
+		{
+			scenario: "interpolation",
+			content:  "HOST=localhost\nURL=http://${HOST}:$PORT/$\nQUOTED=\"${HOST}\"\nMISSING=${MISSING}\n",
+			expected: map[string]string{
+				"HOST":    "localhost",
+				"URL":     "http://localhost:8080/$",
+				"QUOTED":  "localhost",
+				"MISSING": "",
+			},
+		},
+		{
+			scenario: "windows line endings",
+			content:  "HOST=localhost\r\nPORT=8080\r\n",
+			expected: map[string]string{
+				"HOST": "localhost",
+				"PORT": "8080",
+			},
+		},
+		{
+			scenario: "missing equal sign returns an error",
+			content:  "HOST localhost",
+			err:      true,
+		},
+		{
+			scenario: "invalid name returns an error",
+			content:  "-HOST=localhost",
+			err:      true,
+		},
+		{
+			scenario: "unterminated quote returns an error",
+			content:  "HOST=\"localhost",
+			err:      true,
+		},
+		{
+			scenario: "characters after a quoted value return an error",
+			content:  "HOST='localhost' foo",
+			err:      true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			vars := make(map[string]string)
+			lookup := func(k string) (string, bool) {
+				if k == "PORT" {
+					return "8080", true
+				}
+				v, ok := vars[k]
+				return v, ok
+			}
+
+			err := parseDotenv(test.content, vars, lookup)
+			if test.err {
+				require.Error(t, err)
+				t.Log("error:", err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, vars)
+		})
+	}
+}
+
+func TestDotenvSource(t *testing.T) {
+	dir := t.TempDir()
+
+	envFile := filepath.Join(dir, ".env")
+	err := os.WriteFile(envFile, []byte("HOST=localhost\nPORT=8080\nURL=http://$HOST:$PORT\n"), 0644)
+	require.NoError(t, err)
+
+	localFile := filepath.Join(dir, ".env.local")
+	err = os.WriteFile(localFile, []byte("PORT=9090\nLOCAL=$PORT\n"), 0644)
+	require.NoError(t, err)
+
+	s := newDotenvSource(func(k string) (string, bool) {
+		if k == "HOST" {
+			return "example.com", true
+		}
+		return "", false
+	}, []string{envFile, localFile, filepath.Join(dir, "missing")})
+
+	tests := []struct {
+		key      string
+		expected SourceValue
+		ok       bool
+	}{
+		{
+			key:      "HOST",
+			expected: SourceValue{Value: "localhost", Kind: "dotenv", Key: envFile + ":HOST"},
+			ok:       true,
+		},
+		{
+			key:      "PORT",
+			expected: SourceValue{Value: "9090", Kind: "dotenv", Key: localFile + ":PORT"},
+			ok:       true,
+		},
+		{
+			key:      "URL",
+			expected: SourceValue{Value: "http://example.com:8080", Kind: "dotenv", Key: envFile + ":URL"},
+			ok:       true,
+		},
+		{
+			key:      "LOCAL",
+			expected: SourceValue{Value: "9090", Kind: "dotenv", Key: localFile + ":LOCAL"},
+			ok:       true,
+		},
+		{
+			key: "MISSING",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.key, func(t *testing.T) {
+			v, ok, err := s.Lookup("", test.key)
+			require.NoError(t, err)
+			require.Equal(t, test.ok, ok)
+			if ok {
+				require.Equal(t, test.expected, v)
+			}
+		})
+	}
+}
+
+func TestAppEnvFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	envFile := filepath.Join(dir, ".env")
+	err := os.WriteFile(envFile, []byte("export HOST=localhost\nPORT=8080\n"), 0644)
+	require.NoError(t, err)
+
+	invalidFile := filepath.Join(dir, "invalid.env")
+	err = os.WriteFile(invalidFile, []byte("PORT\n"), 0644)
+	require.NoError(t, err)
+
+	t.Run("options are loaded from env files", func(t *testing.T) {
+		var opts struct {
+			Host string
+			Port int
+		}
+
+		app := New("test")
+		app.Args = []string{"--host", "example.com"}
+		app.Stderr = writerNoop{}
+		app.LookupEnv = func(string) (string, bool) { return "", false }
+		app.EnvFiles = []string{envFile}
+		app.Exit = func(code int) {
+			t.Fatalf("unexpected exit with code %v", code)
+		}
+		app.Register().Options(&opts)
+
+		app.Load()
+		require.Equal(t, "example.com", opts.Host)
+		require.Equal(t, 8080, opts.Port)
+	})
+
+	t.Run("invalid env file exits with -1", func(t *testing.T) {
+		code := 0
+
+		app := New("test")
+		app.Args = nil
+		app.Stderr = writerNoop{}
+		app.EnvFiles = []string{invalidFile}
+		app.Exit = func(c int) { code = c }
+		app.Register().Options(&struct {
+			Port int
+		}{})
+
+		app.Load()
+		require.Equal(t, -1, code)
+	})
+}
@@ -0,0 +1,247 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported dump formats.
+const (
+	Text = "text"
+	JSON = "json"
+	Env  = "env"
+)
+
+// Value sources.
+const (
+	sourceDefault = "default"
+	sourceConfig  = "config"
+	sourceEnv     = "env"
+	sourceDotenv  = "dotenv"
+	sourceFile    = "file"
+	sourceFlag    = "flag"
+	sourceArg     = "arg"
+	sourcePrompt  = "prompt"
+)
+
+const (
+	printConfigFlagName = "print-config"
+)
+
+// valueSource describes where an option value comes from.
+type valueSource struct {
+	kind string
+	key  string
+}
+
+func (s valueSource) String() string {
+	switch {
+	case s.kind == "":
+		return sourceDefault
+
+	case s.key == "":
+		return s.kind
+
+	default:
+		return s.kind + ":" + s.key
+	}
+}
+
+// setSource records where the option value comes from. As the option is then
+// configured, the optional structs that contain it are allocated. Sources are
+// not recorded for options that do not track them.
+func (o option) setSource(kind, key string) {
+	o.optional.allocate()
+
+	if o.source != nil {
+		*o.source = valueSource{kind: kind, key: key}
+	}
+}
+
+func (o option) sourceString() string {
+	if o.source == nil {
+		return sourceDefault
+	}
+	return o.source.String()
+}
+
+// Dump writes the options of the command loaded by the default app. See
+// App.Dump.
+func Dump(w io.Writer, format string) error {
+	return defaultApp.Dump(w, format)
+}
+
+// Dump writes the final value of every option of the loaded command, labelled
+// with its source: default, config file, environment variable, flag, argument
+// or prompt. Supported formats are text, json and env. Secret values are
+// masked, and the --config flag added by the package is not written.
+func (a *App) Dump(w io.Writer, format string) error {
+	if a.currentUsage == nil {
+		return errors.New("options are dumped before being loaded")
+	}
+	return dumpOptions(w, format, a.manager.options)
+}
+
+type dumpEntry struct {
+	Name   string      `json:"name"`
+	Env    string      `json:"env,omitempty"`
+	Value  interface{} `json:"value"`
+	Source string      `json:"source"`
+}
+
+func dumpOptions(w io.Writer, format string, opts []option) error {
+	entries := make([]dumpEntry, 0, len(opts))
+	for _, o := range opts {
+		if o.isInternal || (o.value.Kind() == reflect.Struct && !o.isText()) {
+			continue
+		}
+
+		var env string
+		if o.envKey != "-" {
+			env = o.envKey
+		}
+
+		entries = append(entries, dumpEntry{
+			Name:   o.name,
+			Env:    env,
+			Value:  dumpValue(o),
+			Source: o.sourceString(),
+		})
+	}
+
+	switch format {
+	case Text:
+		writeTextDump(w, entries)
+		return nil
+
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(entries)
+
+	case Env:
+		writeEnvDump(w, entries)
+		return nil
+
+	default:
+		return fmt.Errorf("unsupported dump format: %q", format)
+	}
+}
+
+// dumpValue returns the option value as displayed in dumps. Types parsed from
+// text and durations are displayed with their text representation.
+func dumpValue(o option) interface{} {
+	if !o.isConfigured() {
+		return nil
+	}
+
+	if o.isSecret {
+		return redactedValue
+	}
+
+	if _, isDuration := o.value.Interface().(time.Duration); isDuration || o.isText() {
+		return o.String()
+	}
+	return o.value.Interface()
+}
+
+func writeTextDump(w io.Writer, entries []dumpEntry) {
+	values := make([]string, len(entries))
+	nameLen := 0
+	valueLen := 0
+
+	for i, e := range entries {
+		values[i] = dumpString(e.Value)
+
+		if l := len(e.Name); l > nameLen {
+			nameLen = l
+		}
+		if l := len(values[i]); l > valueLen {
+			valueLen = l
+		}
+	}
+
+	for i, e := range entries {
+		fmt.Fprint(w, e.Name)
+		indent(w, nameLen-len(e.Name)+4)
+		fmt.Fprint(w, values[i])
+		indent(w, valueLen-len(values[i])+4)
+		fmt.Fprintln(w, e.Source)
+	}
+}
+
+func writeEnvDump(w io.Writer, entries []dumpEntry) {
+	for _, e := range entries {
+		if e.Env == "" {
+			continue
+		}
+
+		value := dumpString(e.Value)
+		if strings.ContainsAny(value, " \t\n\"'#$\\=`") {
+			value = strconv.Quote(value)
+		}
+		fmt.Fprintf(w, "%s=%s # %s\n", e.Env, value, e.Source)
+	}
+}
+
+// dumpString returns the text representation of a dumped value.
+func dumpString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+
+	case string:
+		return v
+
+	default:
+		o := option{value: reflect.ValueOf(v)}
+		if o.isMultiValue() {
+			return o.multiValueString()
+		}
+
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
+
+// formatFlag is the value of flags that print an output instead of executing
+// the command, such as --print-config. It can be used as a boolean flag to
+// print the output in the first format or be set with one of the formats.
+type formatFlag struct {
+	format  *string
+	formats []string
+}
+
+func (f formatFlag) String() string {
+	if f.format == nil {
+		return ""
+	}
+	return *f.format
+}
+
+func (f formatFlag) Set(s string) error {
+	switch {
+	case s == "true":
+		*f.format = f.formats[0]
+
+	case s == "false":
+		*f.format = ""
+
+	case containsString(f.formats, s):
+		*f.format = s
+
+	default:
+		return fmt.Errorf("unsupported format: %q", s)
+	}
+	return nil
+}
+
+func (f formatFlag) IsBoolFlag() bool {
+	return true
+}
@@ -0,0 +1,197 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type dumpOptionsStruct struct {
+	Host    string
+	Port    int
+	Timeout time.Duration
+	Labels  map[string]string
+	APIKey  string `cli:"api-key,secret"`
+	Nested  struct {
+		Level string
+	}
+	File string `arg:"0"`
+}
+
+func TestDump(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config.yaml")
+	err := os.WriteFile(configFile, []byte("nested:\n  level: debug\n"), 0644)
+	require.NoError(t, err)
+
+	line := func(name, value, source string) string {
+		return fmt.Sprintf("%-16s%-13s%s\n", name, value, source)
+	}
+
+	tests := []struct {
+		scenario string
+		format   string
+		expected string
+		err      bool
+	}{
+		{
+			scenario: "text",
+			format:   Text,
+			expected: "" +
+				line("host", "localhost", "default") +
+				line("port", "8080", "flag:--port") +
+				line("timeout", "1m0s", "env:TIMEOUT") +
+				line("labels", "env=prod", "default") +
+				line("api-key", "******", "env:API_KEY") +
+				line("nested.level", "debug", "config:"+configFile) +
+				line("file", "data.json", "arg"),
+		},
+		{
+			scenario: "env",
+			format:   Env,
+			expected: "" +
+				"HOST=localhost # default\n" +
+				"PORT=8080 # flag:--port\n" +
+				"TIMEOUT=1m0s # env:TIMEOUT\n" +
+				"LABELS=\"env=prod\" # default\n" +
+				"API_KEY=****** # env:API_KEY\n" +
+				"NESTED_LEVEL=debug # config:" + configFile + "\n",
+		},
+		{
+			scenario: "unsupported format returns an error",
+			format:   "xml",
+			err:      true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			opts := dumpOptionsStruct{
+				Host:   "localhost",
+				Port:   42,
+				Labels: map[string]string{"env": "prod"},
+			}
+
+			app := New("test")
+			app.Args = []string{"--port", "8080", "--config", configFile, "data.json"}
+			app.LookupEnv = func(k string) (string, bool) {
+				v, ok := map[string]string{
+					"TIMEOUT": "1m",
+					"API_KEY": "my-api-key",
+				}[k]
+				return v, ok
+			}
+			app.Register().Options(&opts)
+			app.Load()
+
+			w := bytes.NewBuffer(nil)
+			err := app.Dump(w, test.format)
+			if test.err {
+				require.Error(t, err)
+				t.Log("error:", err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, w.String())
+			t.Log(w.String())
+		})
+	}
+}
+
+func TestDumpJSON(t *testing.T) {
+	opts := dumpOptionsStruct{
+		Labels: map[string]string{"env": "prod"},
+		APIKey: "my-api-key",
+	}
+
+	app := New("test")
+	app.Args = []string{"--timeout", "2s", "data.json"}
+	app.LookupEnv = func(string) (string, bool) { return "", false }
+	app.Register().Options(&opts)
+	app.Load()
+
+	w := bytes.NewBuffer(nil)
+	err := app.Dump(w, JSON)
+	require.NoError(t, err)
+	require.NotContains(t, w.String(), "my-api-key")
+
+	var entries []map[string]interface{}
+	err = json.Unmarshal(w.Bytes(), &entries)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"name":   "timeout",
+		"env":    "TIMEOUT",
+		"value":  "2s",
+		"source": "flag:--timeout",
+	}, entries[2])
+	require.Equal(t, map[string]interface{}{"env": "prod"}, entries[3]["value"])
+	t.Log(w.String())
+}
+
+func TestDumpBeforeLoad(t *testing.T) {
+	app := New("test")
+	err := app.Dump(bytes.NewBuffer(nil), Text)
+	require.Error(t, err)
+	t.Log("error:", err)
+}
+
+func TestPrintConfig(t *testing.T) {
+	tests := []struct {
+		scenario string
+		args     []string
+		expected string
+		code     int
+	}{
+		{
+			scenario: "text",
+			args:     []string{"--print-config", "--port", "21"},
+			expected: "port    21    flag:--port\n",
+		},
+		{
+			scenario: "env",
+			args:     []string{"--port", "21", "--print-config=env"},
+			expected: "PORT=21 # flag:--port\n",
+		},
+		{
+			scenario: "invalid format",
+			args:     []string{"--print-config=xml"},
+			code:     -1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			opts := struct {
+				Port int
+			}{}
+
+			stdout := bytes.NewBuffer(nil)
+			code := 42
+
+			app := New("test")
+			app.Args = test.args
+			app.Stdout = stdout
+			app.Stderr = writerNoop{}
+			app.LookupEnv = func(string) (string, bool) { return "", false }
+			app.Exit = func(c int) { code = c }
+
+			var called bool
+			app.Register().Options(&opts).Run(func(context.Context) error {
+				called = true
+				return nil
+			})
+			app.Run(context.TODO())
+
+			require.False(t, called)
+			require.Equal(t, test.code, code)
+			require.Equal(t, test.expected, stdout.String())
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"context"
+)
+
+// Handler is a function that executes a command.
+type Handler func(ctx context.Context) error
+
+// Middleware is a function that wraps the execution of commands, eg. to set up
+// and tear down what every command of a program needs.
+type Middleware func(next Handler) Handler
+
+// Use adds the given middlewares that wrap the execution of every command
+// handler executed by Run. Middlewares are executed in the order they are
+// added, the first one being the outermost.
+func (a *App) Use(mw ...Middleware) {
+	a.middlewares = append(a.middlewares, mw...)
+}
+
+// wrap returns the given handler wrapped by the app middlewares and by the
+// hooks of the given commands, ordered from the root to the executed command.
+func (a *App) wrap(lineage []*command, handler Handler) Handler {
+	h := withHooks(lineage, handler)
+	for i := len(a.middlewares) - 1; i >= 0; i-- {
+		h = a.middlewares[i](h)
+	}
+	return h
+}
+
+// withHooks returns the given handler wrapped by the hooks of the given
+// commands. Before hooks are executed from the first command to the last one,
+// and After hooks in the reverse order. The After hook of a command is only
+// executed when its Before hook succeeded.
+func withHooks(lineage []*command, handler Handler) Handler {
+	if len(lineage) == 0 {
+		return handler
+	}
+
+	c := lineage[0]
+	next := withHooks(lineage[1:], handler)
+
+	return func(ctx context.Context) error {
+		if c.before != nil {
+			if err := c.before(ctx); err != nil {
+				return err
+			}
+		}
+
+		err := next(ctx)
+		if c.after != nil {
+			err = c.after(ctx, err)
+		}
+		return err
+	}
+}
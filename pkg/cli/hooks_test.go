@@ -0,0 +1,193 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHooks(t *testing.T) {
+	errBefore := errors.New("before error")
+	errHandler := errors.New("handler error")
+
+	tests := []struct {
+		scenario      string
+		args          []string
+		rootBefore    error
+		handlerErr    error
+		expectedCalls []string
+		expectedErr   error
+	}{
+		{
+			scenario: "root command",
+			expectedCalls: []string{
+				"middleware 1 start",
+				"middleware 2 start",
+				"root before",
+				"root handler",
+				"root after: <nil>",
+				"middleware 2 end",
+				"middleware 1 end",
+			},
+		},
+		{
+			scenario: "parent hooks run for subcommands",
+			args:     []string{"db", "migrate"},
+			expectedCalls: []string{
+				"middleware 1 start",
+				"middleware 2 start",
+				"root before",
+				"db before",
+				"migrate handler",
+				"db after: <nil>",
+				"root after: <nil>",
+				"middleware 2 end",
+				"middleware 1 end",
+			},
+		},
+		{
+			scenario:   "before error skips the handler",
+			args:       []string{"db", "migrate"},
+			rootBefore: errBefore,
+			expectedCalls: []string{
+				"middleware 1 start",
+				"middleware 2 start",
+				"root before",
+				"middleware 2 end",
+				"middleware 1 end",
+			},
+			expectedErr: errBefore,
+		},
+		{
+			scenario:   "after hooks receive the handler error",
+			args:       []string{"db", "migrate"},
+			handlerErr: errHandler,
+			expectedCalls: []string{
+				"middleware 1 start",
+				"middleware 2 start",
+				"root before",
+				"db before",
+				"migrate handler",
+				"db after: handler error",
+				"root after: handler error",
+				"middleware 2 end",
+				"middleware 1 end",
+			},
+			expectedErr: errHandler,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			var calls []string
+			call := func(s string) {
+				calls = append(calls, s)
+			}
+
+			app := New("test")
+			app.Args = test.args
+			app.Stderr = writerNoop{}
+			app.Exit = func(int) {}
+
+			for _, name := range []string{"1", "2"} {
+				name := name
+				app.Use(func(next Handler) Handler {
+					return func(ctx context.Context) error {
+						call("middleware " + name + " start")
+						err := next(ctx)
+						call("middleware " + name + " end")
+						return err
+					}
+				})
+			}
+
+			app.Register().
+				Before(func(context.Context) error {
+					call("root before")
+					return test.rootBefore
+				}).
+				After(func(ctx context.Context, err error) error {
+					call("root after: " + errorString(err))
+					return err
+				}).
+				Run(func(context.Context) error {
+					call("root handler")
+					return nil
+				})
+
+			app.Register("db").
+				Before(func(context.Context) error {
+					call("db before")
+					return nil
+				}).
+				After(func(ctx context.Context, err error) error {
+					call("db after: " + errorString(err))
+					return err
+				})
+
+			app.Register("db", "migrate").
+				Run(func(context.Context) error {
+					call("migrate handler")
+					return test.handlerErr
+				})
+
+			c, err := app.load()
+			require.NoError(t, err)
+
+			handler := app.wrap(commandLineage(app.manager.commands, c.name), c.handler)
+			err = handler(context.TODO())
+			require.Equal(t, test.expectedErr, err)
+			require.Equal(t, test.expectedCalls, calls)
+		})
+	}
+}
+
+func TestHooksAfterReplacesError(t *testing.T) {
+	code := 0
+
+	app := New("test")
+	app.Args = nil
+	app.Stderr = writerNoop{}
+	app.Exit = func(c int) { code = c }
+	app.Register().
+		After(func(ctx context.Context, err error) error {
+			return WithExitCode(err, 3)
+		}).
+		Run(func(context.Context) error {
+			return errors.New("handler error")
+		})
+
+	app.Run(context.TODO())
+	require.Equal(t, 3, code)
+}
+
+func TestHooksSkipBuiltinCommands(t *testing.T) {
+	var called bool
+
+	app := New("test")
+	app.Args = []string{"version"}
+	app.Stdout = writerNoop{}
+	app.Stderr = writerNoop{}
+	app.Exit = func(int) {}
+	app.Use(func(next Handler) Handler {
+		called = true
+		return next
+	})
+	app.Register().Before(func(context.Context) error {
+		called = true
+		return nil
+	})
+	app.RegisterVersion()
+
+	app.Run(context.TODO())
+	require.False(t, called)
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return "<nil>"
+	}
+	return err.Error()
+}
@@ -0,0 +1,204 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	defaultSeparator = ","
+)
+
+// isMultiValue reports whether the option is a slice or a map that is set from
+// separated values. Byte slices and types parsed from text are excluded.
+func (o option) isMultiValue() bool {
+	if o.isText() {
+		return false
+	}
+
+	switch t := o.value.Type(); t.Kind() {
+	case reflect.Slice:
+		return t.Elem().Kind() != reflect.Uint8
+
+	case reflect.Map:
+		return true
+
+	default:
+		return false
+	}
+}
+
+func (o option) separatorOrDefault() string {
+	if o.separator == "" {
+		return defaultSeparator
+	}
+	return o.separator
+}
+
+// setMultiValue sets a slice option from separated values (eg. "a,b") and a map
+// option from separated key/value pairs (eg. "k=v,k2=v2"). JSON arrays and
+// objects are also supported.
+func (o option) setMultiValue(s string) error {
+	t := o.value.Type()
+
+	if (t.Kind() == reflect.Slice && strings.HasPrefix(s, "[") ||
+		t.Kind() == reflect.Map && strings.HasPrefix(s, "{")) && json.Valid([]byte(s)) {
+		v := reflect.New(t)
+		if err := json.Unmarshal([]byte(s), v.Interface()); err != nil {
+			return err
+		}
+		o.value.Set(v.Elem())
+		return nil
+	}
+
+	values := o.splitMultiValue(s)
+
+	switch t.Kind() {
+	case reflect.Slice:
+		slice := reflect.MakeSlice(t, 0, len(values))
+		for _, v := range values {
+			elem := reflect.New(t.Elem()).Elem()
+			if err := (option{value: elem}).Set(v); err != nil {
+				return err
+			}
+			slice = reflect.Append(slice, elem)
+		}
+		o.value.Set(slice)
+
+	case reflect.Map:
+		m := reflect.MakeMapWithSize(t, len(values))
+		for _, pair := range values {
+			k, v, ok := strings.Cut(pair, "=")
+			if !ok {
+				return fmt.Errorf("%q is not a key=value pair", pair)
+			}
+
+			key := reflect.New(t.Key()).Elem()
+			if err := (option{value: key}).Set(k); err != nil {
+				return err
+			}
+
+			val := reflect.New(t.Elem()).Elem()
+			if err := (option{value: val}).Set(v); err != nil {
+				return err
+			}
+
+			m.SetMapIndex(key, val)
+		}
+		o.value.Set(m)
+	}
+
+	return nil
+}
+
+// splitMultiValue splits the given string with the option separator. Values of
+// composite types, which are JSON encoded, are not split unless they are parsed
+// from text.
+func (o option) splitMultiValue(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	elem := reflect.New(o.value.Type().Elem()).Elem()
+	if (option{value: elem}).isText() {
+		return strings.Split(s, o.separatorOrDefault())
+	}
+
+	switch elem.Kind() {
+	case reflect.Struct,
+		reflect.Map,
+		reflect.Slice,
+		reflect.Array:
+		return []string{s}
+
+	default:
+		return strings.Split(s, o.separatorOrDefault())
+	}
+}
+
+// merge appends the values parsed from the given string to a slice option, or
+// adds them to a map option.
+func (o option) merge(s string) error {
+	v := reflect.New(o.value.Type()).Elem()
+	if err := (option{value: v, separator: o.separator}).Set(s); err != nil {
+		return err
+	}
+
+	switch o.value.Kind() {
+	case reflect.Slice:
+		o.value.Set(reflect.AppendSlice(o.value, v))
+
+	case reflect.Map:
+		if o.value.IsNil() {
+			o.value.Set(reflect.MakeMap(o.value.Type()))
+		}
+
+		iter := v.MapRange()
+		for iter.Next() {
+			o.value.SetMapIndex(iter.Key(), iter.Value())
+		}
+	}
+
+	return nil
+}
+
+// multiValueString returns the separated representation of a slice or map
+// option.
+func (o option) multiValueString() string {
+	sep := o.separatorOrDefault()
+
+	switch o.value.Kind() {
+	case reflect.Slice:
+		values := make([]string, 0, o.value.Len())
+		for i := 0; i < o.value.Len(); i++ {
+			values = append(values, option{value: o.value.Index(i)}.String())
+		}
+		return strings.Join(values, sep)
+
+	case reflect.Map:
+		values := make([]string, 0, o.value.Len())
+		iter := o.value.MapRange()
+		for iter.Next() {
+			k := option{value: iter.Key()}.String()
+			v := option{value: iter.Value()}.String()
+			values = append(values, k+"="+v)
+		}
+		sort.Strings(values)
+		return strings.Join(values, sep)
+
+	default:
+		return ""
+	}
+}
+
+// flagValue is the flag value of an option. Slice and map options are replaced
+// by the first flag occurrence and merged with the following ones.
+type flagValue struct {
+	option
+	isSet bool
+
+	// The error of a secret value that failed to be set. It replaces the flag
+	// parsing error, which contains the value.
+	secretErr error
+}
+
+func (f *flagValue) Set(s string) error {
+	return f.set(flagName(f.name), s)
+}
+
+func (f *flagValue) set(name, s string) error {
+	if f.isSet && f.isMultiValue() {
+		return f.redactError(name, f.merge(s))
+	}
+
+	f.isSet = true
+	if err := f.option.Set(s); err != nil {
+		return f.redactError(name, err)
+	}
+
+	f.setSource(sourceFlag, "--"+f.name)
+	return nil
+}
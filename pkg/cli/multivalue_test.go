@@ -0,0 +1,223 @@
+package cli
+
+import (
+	"flag"
+	"net"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type multiValueOptions struct {
+	Hosts     []string `short:"H"`
+	Ports     []int    `sep:";"`
+	Timeouts  []time.Duration
+	Labels    map[string]string
+	Weights   map[string]float64
+	Bytes     []byte
+	Nested    []nestedStruct
+	Addresses []string
+}
+
+func TestMultiValueOptions(t *testing.T) {
+	tests := []struct {
+		scenario string
+		env      map[string]string
+		args     []string
+		expected multiValueOptions
+		err      bool
+	}{
+		{
+			scenario: "default values",
+			expected: multiValueOptions{
+				Hosts:     []string{"localhost"},
+				Labels:    map[string]string{"env": "dev"},
+				Addresses: []string{"127.0.0.1"},
+			},
+		},
+		{
+			scenario: "values from env",
+			env: map[string]string{
+				"HOSTS":    "a,b",
+				"PORTS":    "80;443",
+				"TIMEOUTS": "1s,2m",
+				"LABELS":   "env=prod,team=infra",
+				"WEIGHTS":  "a=0.5",
+			},
+			expected: multiValueOptions{
+				Hosts:     []string{"a", "b"},
+				Ports:     []int{80, 443},
+				Timeouts:  []time.Duration{time.Second, 2 * time.Minute},
+				Labels:    map[string]string{"env": "prod", "team": "infra"},
+				Weights:   map[string]float64{"a": 0.5},
+				Addresses: []string{"127.0.0.1"},
+			},
+		},
+		{
+			scenario: "empty value from env",
+			env: map[string]string{
+				"HOSTS": "",
+			},
+			expected: multiValueOptions{
+				Hosts:     []string{},
+				Labels:    map[string]string{"env": "dev"},
+				Addresses: []string{"127.0.0.1"},
+			},
+		},
+		{
+			scenario: "repeated flags",
+			env: map[string]string{
+				"HOSTS": "a,b",
+			},
+			args: []string{
+				"-hosts", "c",
+				"-H", "d,e",
+				"--labels", "team=infra",
+				"--labels", "env=prod,region=eu",
+				"-addresses", `["10.0.0.1","10.0.0.2"]`,
+				"-addresses", "10.0.0.3",
+			},
+			expected: multiValueOptions{
+				Hosts:     []string{"c", "d", "e"},
+				Labels:    map[string]string{"team": "infra", "env": "prod", "region": "eu"},
+				Addresses: []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"},
+			},
+		},
+		{
+			scenario: "json values",
+			args: []string{
+				"-hosts", `["a,b","c"]`,
+				"-labels", `{"env":"prod"}`,
+				"-bytes", `"aGVsbG8="`,
+				"-nested", `{"Float":1.5}`,
+				"-nested", `{"Float":2.5}`,
+			},
+			expected: multiValueOptions{
+				Hosts:     []string{"a,b", "c"},
+				Labels:    map[string]string{"env": "prod"},
+				Bytes:     []byte("hello"),
+				Nested:    []nestedStruct{{Float: 1.5}, {Float: 2.5}},
+				Addresses: []string{"127.0.0.1"},
+			},
+		},
+		{
+			scenario: "invalid element returns an error",
+			args:     []string{"-ports", "80;http"},
+			err:      true,
+		},
+		{
+			scenario: "invalid pair returns an error",
+			args:     []string{"-labels", "env"},
+			err:      true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			for k, v := range test.env {
+				os.Setenv(k, v)
+				defer os.Unsetenv(k)
+			}
+
+			opts := multiValueOptions{
+				Hosts:     []string{"localhost"},
+				Labels:    map[string]string{"env": "dev"},
+				Addresses: []string{"127.0.0.1"},
+			}
+
+			p := optionParser{flags: flag.NewFlagSet("test", flag.ContinueOnError)}
+			p.flags.SetOutput(writerNoop{})
+
+			_, err := p.parse(&opts)
+			require.NoError(t, err)
+
+			err = p.flags.Parse(test.args)
+			if test.err {
+				require.Error(t, err)
+				t.Log("error:", err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, opts)
+		})
+	}
+}
+
+func TestTextMultiValueOptions(t *testing.T) {
+	var opts struct {
+		IPs   []net.IP
+		Times []time.Time
+	}
+
+	p := optionParser{
+		flags: flag.NewFlagSet("test", flag.ContinueOnError),
+		lookupEnv: func(k string) (string, bool) {
+			return "2024-01-01T00:00:00Z,2024-01-02T00:00:00Z", k == "TIMES"
+		},
+	}
+	p.flags.SetOutput(writerNoop{})
+
+	_, err := p.parse(&opts)
+	require.NoError(t, err)
+	require.Equal(t, []time.Time{
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+	}, opts.Times)
+
+	err = p.flags.Parse([]string{"--ips", "1.1.1.1,2.2.2.2"})
+	require.NoError(t, err)
+	require.Equal(t, []net.IP{net.ParseIP("1.1.1.1"), net.ParseIP("2.2.2.2")}, opts.IPs)
+
+	err = p.flags.Parse([]string{"--ips", "1.1.1.1,a"})
+	require.Error(t, err)
+	t.Log("error:", err)
+}
+
+func TestMultiValueString(t *testing.T) {
+	tests := []struct {
+		scenario  string
+		value     interface{}
+		separator string
+		expected  string
+	}{
+		{
+			scenario: "slice",
+			value:    []string{"a", "b"},
+			expected: "a,b",
+		},
+		{
+			scenario:  "slice with separator",
+			value:     []int{80, 443},
+			separator: ";",
+			expected:  "80;443",
+		},
+		{
+			scenario: "slice of durations",
+			value:    []time.Duration{time.Second},
+			expected: "1s",
+		},
+		{
+			scenario: "map",
+			value:    map[string]int{"b": 2, "a": 1},
+			expected: "a=1,b=2",
+		},
+		{
+			scenario: "byte slice",
+			value:    []byte("hello"),
+			expected: `"aGVsbG8="`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			o := option{
+				value:     reflect.ValueOf(test.value),
+				separator: test.separator,
+			}
+			require.Equal(t, test.expected, o.String())
+		})
+	}
+}
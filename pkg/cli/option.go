@@ -4,7 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"flag"
-	"os"
+	"fmt"
 	"reflect"
 	"strconv"
 	"strings"
@@ -12,53 +12,186 @@ import (
 )
 
 type optionParser struct {
-	flags   *flag.FlagSet
-	options []option
+	flags          *flag.FlagSet
+	lookupEnv      func(string) (string, bool)
+	sources        []Source
+	persistent     []interface{}
+	configFile     string
+	usesConfigFile bool
+	options        []option
 }
 
 func (p *optionParser) parse(v interface{}) ([]option, error) {
-	p.options = nil
-
-	if v == nil {
-		return nil, nil
+	if err := p.collect(v); err != nil || p.options == nil {
+		return nil, err
 	}
 
-	val := reflect.ValueOf(v)
-
-	if val.Kind() != reflect.Ptr {
-		return nil, errors.New("receiver is not a pointer")
+	if p.usesConfigFile && p.configFile != "" {
+		if err := loadConfigFile(p.configFile, p.options); err != nil {
+			return nil, err
+		}
 	}
 
-	if val = val.Elem(); val.Kind() != reflect.Struct {
-		return nil, errors.New("receiver does not point to a struct")
+	sources := p.sources
+	if sources == nil {
+		sources = []Source{EnvSource(p.lookupEnv)}
 	}
+	sources = bindSources(sources, p.options)
 
-	p.parseStruct("", val)
+	for _, o := range p.options {
+		if o.envKey == "-" {
+			continue
+		}
+
+		for _, s := range sources {
+			if err := o.loadSource(s); err != nil {
+				return nil, err
+			}
+		}
+	}
 
 	for _, o := range p.options {
-		if o.name != "h" && o.name != "help" {
-			p.flags.Var(o, o.name, o.help)
+		f := &flagValue{option: o}
+
+		if o.name != "h" && o.name != "help" && !o.isArg {
+			p.flags.Var(f, o.name, o.help)
+		}
+
+		if o.short != "" {
+			p.flags.Var(f, o.short, o.help)
+		}
+
+		for _, name := range o.deprecatedFlags {
+			p.flags.Var(deprecatedFlagValue{flagValue: f, name: name}, name, o.help)
 		}
 	}
 
 	return p.options, nil
 }
 
-func (p *optionParser) parseStruct(prefix string, v reflect.Value) {
+// collect stores the options described by the given receiver and by the
+// persistent receivers without loading their values.
+func (p *optionParser) collect(v interface{}) error {
+	p.options = nil
+	p.usesConfigFile = false
+
+	if v == nil && len(p.persistent) == 0 {
+		return nil
+	}
+
+	if v != nil {
+		val, err := receiverStruct(v)
+		if err != nil {
+			return err
+		}
+
+		if err := p.parseStruct("", nil, val, nil); err != nil {
+			return err
+		}
+	}
+
+	for _, pv := range p.persistent {
+		val, err := receiverStruct(pv)
+		if err != nil {
+			return fmt.Errorf("persistent options: %w", err)
+		}
+
+		n := len(p.options)
+		if err := p.parseStruct("", nil, val, nil); err != nil {
+			return err
+		}
+
+		for i := n; i < len(p.options); i++ {
+			if p.options[i].isArg {
+				return fmt.Errorf("persistent option %q cannot be an argument", p.options[i].name)
+			}
+			p.options[i].isPersistent = true
+		}
+	}
+
+	if err := checkNames(p.options); err != nil {
+		return err
+	}
+
+	if err := checkArgs(p.options); err != nil {
+		return err
+	}
+
+	if err := checkShorts(p.options); err != nil {
+		return err
+	}
+
+	if err := checkDeprecated(p.options); err != nil {
+		return err
+	}
+
+	if !hasOption(p.options, configOptionName) {
+		p.usesConfigFile = true
+		p.options = append(p.options, option{
+			name:       configOptionName,
+			help:       "The path of a JSON, YAML or TOML file to load options from.",
+			envKey:     "-",
+			value:      reflect.ValueOf(&p.configFile).Elem(),
+			source:     &valueSource{},
+			isInternal: true,
+		})
+	}
+
+	return nil
+}
+
+func receiverStruct(v interface{}) (reflect.Value, error) {
+	val := reflect.ValueOf(v)
+
+	if val.Kind() != reflect.Ptr {
+		return reflect.Value{}, errors.New("receiver is not a pointer")
+	}
+
+	if val = val.Elem(); val.Kind() != reflect.Struct {
+		return reflect.Value{}, errors.New("receiver does not point to a struct")
+	}
+
+	return val, nil
+}
+
+func (p *optionParser) parseStruct(prefix string, configPrefix []string, v reflect.Value, optional *optionalValue) error {
 	for i := 0; i < v.NumField(); i++ {
 		fval := v.Field(i)
 		if !fval.CanSet() {
 			continue
 		}
 
+		value := fval
+		fieldOptional := optional
+		isOptional := false
+
+		switch {
+		case isOptionalField(fval):
+			fieldOptional, value = newOptionalValue(fval, optional)
+			isOptional = true
+
+		case fval.Kind() == reflect.Interface && !fval.IsNil() &&
+			fval.Elem().Kind() == reflect.Ptr && fval.Elem().Elem().Kind() == reflect.Struct:
+			value = fval.Elem().Elem()
+		}
+
 		finfo := v.Type().Field(i)
-		fname, modifier := parseTag(finfo.Tag.Get("cli"))
+		fname, modifiers := parseTag(finfo.Tag.Get("cli"))
 
 		if fname == "" {
 			fname = finfo.Name
 		}
 		fname = normalizeCLIOptionName(fname)
 
+		var configPath []string
+		if configKey := finfo.Tag.Get("config"); configKey != "-" && (configPrefix != nil || prefix == "") {
+			if configKey == "" {
+				configKey = fname
+			}
+			configPath = append(configPath, configPrefix...)
+			configPath = append(configPath, configKey)
+		}
+
 		if prefix != "" {
 			fname = prefix + "." + fname
 		}
@@ -68,40 +201,112 @@ func (p *optionParser) parseStruct(prefix string, v reflect.Value) {
 			envKey = normalizeEnvOptionName(fname)
 		}
 
+		rules, err := parseValidateTag(finfo.Tag.Get("validate"))
+		if err != nil {
+			return fmt.Errorf("option %q: %w", fname, err)
+		}
+
+		deprecatedEnvKeys, deprecatedFlags, err := parseDeprecatedTag(finfo.Tag.Get("deprecated"))
+		if err != nil {
+			return fmt.Errorf("option %q: %w", fname, err)
+		}
+
 		o := option{
-			name:     fname,
-			help:     finfo.Tag.Get("help"),
-			envKey:   envKey,
-			value:    fval,
-			isHidden: modifier == "hidden",
+			name:              fname,
+			help:              finfo.Tag.Get("help"),
+			prompt:            finfo.Tag.Get("prompt"),
+			envKey:            envKey,
+			configPath:        configPath,
+			value:             value,
+			rules:             rules,
+			short:             finfo.Tag.Get("short"),
+			separator:         finfo.Tag.Get("sep"),
+			isHidden:          containsString(modifiers, "hidden"),
+			isSecret:          containsString(modifiers, "secret"),
+			deprecatedEnvKeys: deprecatedEnvKeys,
+			deprecatedFlags:   deprecatedFlags,
+			isOptional:        isOptional,
+			optional:          fieldOptional,
+			source:            &valueSource{},
+		}
+
+		if err := checkRuleParams(o); err != nil {
+			return fmt.Errorf("option %q: %w", fname, err)
 		}
 
-		if envVal, ok := os.LookupEnv(envKey); ok && envKey != "-" {
-			o.Set(envVal)
+		if arg, ok := finfo.Tag.Lookup("arg"); ok {
+			if err := parseArg(&o, arg); err != nil {
+				return fmt.Errorf("option %q: %w", fname, err)
+			}
+			p.options = append(p.options, o)
+			continue
 		}
 
 		p.options = append(p.options, o)
 
-		if fval.Kind() == reflect.Struct {
-			p.parseStruct(fname, fval)
+		if value.Kind() == reflect.Struct && !o.isText() {
+			if err := p.parseStruct(fname, configPath, value, fieldOptional); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkNames ensures that option names are unique. Names can collide when
+// persistent options are inherited from parent commands.
+func checkNames(opts []option) error {
+	names := make(map[string]bool, len(opts))
+	for _, o := range opts {
+		if names[o.name] {
+			return fmt.Errorf("option %q is defined multiple times", o.name)
 		}
+		names[o.name] = true
 	}
+	return nil
 }
 
-func parseTag(tag string) (value string, modifier string) {
-	s := strings.SplitN(tag, ",", 2)
-	if len(s) == 2 {
-		modifier = s[1]
+func hasOption(opts []option, name string) bool {
+	for _, o := range opts {
+		if o.name == name {
+			return true
+		}
 	}
-	return s[0], modifier
+	return false
+}
+
+func parseTag(tag string) (value string, modifiers []string) {
+	s := strings.Split(tag, ",")
+	for _, m := range s[1:] {
+		if m != "" {
+			modifiers = append(modifiers, m)
+		}
+	}
+	return s[0], modifiers
 }
 
 type option struct {
-	name     string
-	help     string
-	envKey   string
-	value    reflect.Value
-	isHidden bool
+	name              string
+	help              string
+	prompt            string
+	envKey            string
+	configPath        []string
+	value             reflect.Value
+	rules             []validationRule
+	isHidden          bool
+	isSecret          bool
+	isPersistent      bool
+	isInternal        bool
+	deprecatedEnvKeys []string
+	deprecatedFlags   []string
+	isOptional        bool
+	optional          *optionalValue
+	short             string
+	separator         string
+	isArg             bool
+	argIndex          int
+	source            *valueSource
 }
 
 func (o option) IsBoolFlag() bool {
@@ -109,14 +314,17 @@ func (o option) IsBoolFlag() bool {
 }
 
 func (o option) String() string {
+	if s, ok := o.textString(); ok {
+		return s
+	}
+
 	switch o.value.Kind() {
 	case reflect.String:
 		return o.value.String()
 	}
 
-	switch value := o.value.Interface().(type) {
-	case time.Duration:
-		return value.String()
+	if o.isMultiValue() {
+		return o.multiValueString()
 	}
 
 	b, _ := json.Marshal(o.value.Interface())
@@ -124,6 +332,10 @@ func (o option) String() string {
 }
 
 func (o option) Set(s string) error {
+	if ok, err := o.setText(s); ok {
+		return err
+	}
+
 	switch o.value.Kind() {
 	case reflect.String:
 		o.value.SetString(s)
@@ -138,9 +350,25 @@ func (o option) Set(s string) error {
 		s = strconv.Quote(s)
 	}
 
+	if o.isMultiValue() {
+		return o.setMultiValue(s)
+	}
+
 	return json.Unmarshal([]byte(s), o.value.Addr().Interface())
 }
 
+// append appends the value parsed from the given string to the option slice
+// value.
+func (o option) append(s string) error {
+	elem := reflect.New(o.value.Type().Elem()).Elem()
+	if err := (option{value: elem}).Set(s); err != nil {
+		return err
+	}
+
+	o.value.Set(reflect.Append(o.value, elem))
+	return nil
+}
+
 func setDuration(v reflect.Value, s string) error {
 	d, err := time.ParseDuration(s)
 	if err != nil {
@@ -472,10 +472,10 @@ func BenchmarkNormalizeOptionName(b *testing.B) {
 
 func TestParseTag(t *testing.T) {
 	utests := []struct {
-		scenario string
-		tag      string
-		value    string
-		modifier string
+		scenario  string
+		tag       string
+		value     string
+		modifiers []string
 	}{
 		{
 			scenario: "tag value",
@@ -486,27 +486,32 @@ func TestParseTag(t *testing.T) {
 			scenario: "tag value and empty modifer",
 			tag:      "foo,",
 			value:    "foo",
-			modifier: "",
 		},
 		{
-			scenario: "tag value and modifer",
-			tag:      "foo,hidden",
-			value:    "foo",
-			modifier: "hidden",
+			scenario:  "tag value and modifer",
+			tag:       "foo,hidden",
+			value:     "foo",
+			modifiers: []string{"hidden"},
+		},
+		{
+			scenario:  "empty tag value and modifer",
+			tag:       ",hidden",
+			value:     "",
+			modifiers: []string{"hidden"},
 		},
 		{
-			scenario: "empty tag value and modifer",
-			tag:      ",hidden",
-			value:    "",
-			modifier: "hidden",
+			scenario:  "tag value and multiple modifiers",
+			tag:       "foo,hidden,secret",
+			value:     "foo",
+			modifiers: []string{"hidden", "secret"},
 		},
 	}
 
 	for _, u := range utests {
 		t.Run(u.scenario, func(t *testing.T) {
-			value, modifier := parseTag(u.tag)
+			value, modifiers := parseTag(u.tag)
 			require.Equal(t, u.value, value)
-			require.Equal(t, u.modifier, modifier)
+			require.Equal(t, u.modifiers, modifiers)
 		})
 	}
 }
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"reflect"
+)
+
+// optionalValue is a pointer field that is only allocated when its option,
+// or one of its nested options, is set. A nil pointer means that the option
+// is not configured.
+type optionalValue struct {
+	field  reflect.Value
+	value  reflect.Value
+	parent *optionalValue
+}
+
+// newOptionalValue returns the optional value of the given pointer field and
+// the value that its option loads. The value of a nil pointer is allocated
+// apart and is assigned to the field once it is set.
+func newOptionalValue(field reflect.Value, parent *optionalValue) (*optionalValue, reflect.Value) {
+	v := &optionalValue{
+		field:  field,
+		value:  field,
+		parent: parent,
+	}
+
+	if field.IsNil() {
+		v.value = reflect.New(field.Type().Elem())
+	}
+	return v, v.value.Elem()
+}
+
+// allocate assigns the allocated values of the optional value and of its
+// parents to their nil pointer fields.
+func (v *optionalValue) allocate() {
+	if v == nil {
+		return
+	}
+
+	v.parent.allocate()
+	if v.field.IsNil() {
+		v.field.Set(v.value)
+	}
+}
+
+// isConfigured reports whether the optional value and its parents are
+// allocated.
+func (v *optionalValue) isConfigured() bool {
+	if v == nil {
+		return true
+	}
+	return !v.field.IsNil() && v.parent.isConfigured()
+}
+
+// isOptionalField reports whether the given field is loaded as an optional
+// value. Pointers to pointers are loaded as they are.
+func isOptionalField(field reflect.Value) bool {
+	return field.Kind() == reflect.Ptr && field.Type().Elem().Kind() != reflect.Ptr
+}
+
+// isOptionalGroup reports whether the option is an optional nested struct.
+func (o option) isOptionalGroup() bool {
+	return o.isOptional && o.value.Kind() == reflect.Struct && !o.isText()
+}
+
+// isConfigured reports whether the option and the nested structs that
+// contain it are allocated.
+func (o option) isConfigured() bool {
+	return o.optional.isConfigured()
+}
+
+// isParentConfigured reports whether the nested structs that contain the
+// option are allocated.
+func (o option) isParentConfigured() bool {
+	if o.isOptional {
+		return o.optional.parent.isConfigured()
+	}
+	return o.optional.isConfigured()
+}
+
+// isZero reports whether the option value is the zero value. Optional values
+// are zero when they are not configured.
+func (o option) isZero() bool {
+	if o.isOptional {
+		return !o.isConfigured()
+	}
+	return o.value.IsZero()
+}
+
+// optionHelp returns the help of the given option as displayed in usages.
+func optionHelp(o option) string {
+	if o.isOptionalGroup() {
+		return "(optional) " + o.help
+	}
+	return o.help
+}
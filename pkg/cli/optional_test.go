@@ -0,0 +1,198 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type tlsOptions struct {
+	Cert string `validate:"required"`
+	Key  string
+}
+
+type proxyOptions struct {
+	Host string
+	Auth *struct {
+		User string
+	}
+}
+
+type optionalOptions struct {
+	TLS     *tlsOptions `help:"TLS settings."`
+	Proxy   *proxyOptions
+	Retries *int `validate:"min=1"`
+	Name    *string
+}
+
+func TestOptionalOptions(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config.yaml")
+	err := os.WriteFile(configFile, []byte("tls:\n  cert: config.pem\n"), 0644)
+	require.NoError(t, err)
+
+	intPtr := func(v int) *int { return &v }
+	stringPtr := func(v string) *string { return &v }
+
+	tests := []struct {
+		scenario string
+		env      map[string]string
+		args     []string
+		expected optionalOptions
+		err      bool
+	}{
+		{
+			scenario: "nothing configured",
+		},
+		{
+			scenario: "nested option from flag",
+			args:     []string{"--tls.cert", "cert.pem"},
+			expected: optionalOptions{TLS: &tlsOptions{Cert: "cert.pem"}},
+		},
+		{
+			scenario: "nested option from env",
+			env:      map[string]string{"TLS_KEY": "key.pem", "TLS_CERT": "cert.pem"},
+			expected: optionalOptions{TLS: &tlsOptions{Cert: "cert.pem", Key: "key.pem"}},
+		},
+		{
+			scenario: "nested option from config file",
+			args:     []string{"--config", configFile},
+			expected: optionalOptions{TLS: &tlsOptions{Cert: "config.pem"}},
+		},
+		{
+			scenario: "nested struct from json",
+			args:     []string{"--tls", `{"cert":"cert.pem"}`},
+			expected: optionalOptions{TLS: &tlsOptions{Cert: "cert.pem"}},
+		},
+		{
+			scenario: "deeply nested option allocates parents",
+			args:     []string{"--proxy.auth.user", "bob"},
+			expected: optionalOptions{Proxy: &proxyOptions{
+				Auth: &struct{ User string }{User: "bob"},
+			}},
+		},
+		{
+			scenario: "optional values",
+			args:     []string{"--retries", "3", "--name", "foo"},
+			expected: optionalOptions{Retries: intPtr(3), Name: stringPtr("foo")},
+		},
+		{
+			scenario: "optional value set to zero",
+			args:     []string{"--name", ""},
+			expected: optionalOptions{Name: stringPtr("")},
+		},
+		{
+			scenario: "invalid optional value returns an error",
+			args:     []string{"--retries", "0"},
+			err:      true,
+		},
+		{
+			scenario: "missing required nested option returns an error",
+			args:     []string{"--tls.key", "key.pem"},
+			err:      true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			var opts optionalOptions
+
+			m := commandManager{
+				out: writerNoop{},
+				lookupEnv: func(k string) (string, bool) {
+					v, ok := test.env[k]
+					return v, ok
+				},
+			}
+			m.register().Options(&opts)
+
+			_, _, err := m.parse(test.args...)
+			if test.err {
+				require.Error(t, err)
+				t.Log("error:", err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, opts)
+		})
+	}
+}
+
+func TestOptionalOptionsDefaults(t *testing.T) {
+	opts := optionalOptions{
+		TLS: &tlsOptions{Cert: "default.pem"},
+	}
+
+	p := optionParser{
+		flags:     flag.NewFlagSet("test", flag.ContinueOnError),
+		lookupEnv: func(string) (string, bool) { return "", false },
+	}
+
+	o, err := p.parse(&opts)
+	require.NoError(t, err)
+
+	err = p.flags.Parse([]string{"--tls.key", "key.pem"})
+	require.NoError(t, err)
+	require.Equal(t, &tlsOptions{Cert: "default.pem", Key: "key.pem"}, opts.TLS)
+	require.NoError(t, validateOptions(o))
+}
+
+func TestInterfaceOptions(t *testing.T) {
+	opts := struct {
+		Driver interface{}
+	}{
+		Driver: &struct {
+			Host string
+		}{},
+	}
+
+	p := optionParser{
+		flags:     flag.NewFlagSet("test", flag.ContinueOnError),
+		lookupEnv: func(string) (string, bool) { return "", false },
+	}
+
+	_, err := p.parse(&opts)
+	require.NoError(t, err)
+
+	err = p.flags.Parse([]string{"--driver.host", "localhost"})
+	require.NoError(t, err)
+	require.Equal(t, &struct{ Host string }{Host: "localhost"}, opts.Driver)
+}
+
+func TestOptionalOptionsUsage(t *testing.T) {
+	var opts optionalOptions
+
+	var p optionParser
+	err := p.collect(&opts)
+	require.NoError(t, err)
+
+	w := bytes.NewBuffer(nil)
+	commandUsage(&noColorWriter{w: w}, defaultUsageWidth, "test", &command{}, p.options, nil)()
+	require.Contains(t, w.String(), "(optional) TLS settings.")
+	require.Nil(t, opts.TLS)
+	t.Log(w.String())
+}
+
+func TestDumpOptionalOptions(t *testing.T) {
+	var opts optionalOptions
+
+	m := commandManager{
+		out:       writerNoop{},
+		lookupEnv: func(string) (string, bool) { return "", false },
+	}
+	m.register().Options(&opts)
+
+	_, _, err := m.parse("--retries", "2")
+	require.NoError(t, err)
+
+	w := bytes.NewBuffer(nil)
+	err = dumpOptions(w, Env, m.options)
+	require.NoError(t, err)
+	require.Contains(t, w.String(), "TLS_CERT= # default\n")
+	require.Contains(t, w.String(), "RETRIES=2 # flag:--retries\n")
+	t.Log(w.String())
+}
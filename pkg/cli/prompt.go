@@ -0,0 +1,224 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	nonInteractiveFlagName = "non-interactive"
+)
+
+// promptOptions asks the values of the options that have a prompt and that are
+// not set by a config file, a source, a flag or an argument. Options are only
+// prompted when the given input is a terminal.
+func promptOptions(in io.Reader, out io.Writer, opts []option) error {
+	if !hasPrompts(opts) {
+		return nil
+	}
+
+	f, ok := in.(*os.File)
+	if !ok || !isTerminal(f) {
+		return nil
+	}
+
+	p := prompter{
+		in:  f,
+		out: out,
+		disableEcho: func() (func(), error) {
+			return disableEcho(f)
+		},
+	}
+	return p.prompt(opts)
+}
+
+func hasPrompts(opts []option) bool {
+	for _, o := range opts {
+		if o.prompt != "" {
+			return true
+		}
+	}
+	return false
+}
+
+type prompter struct {
+	in  io.Reader
+	out io.Writer
+
+	// The function that disables the input echo while secret values are
+	// typed. It returns the function that restores the echo.
+	disableEcho func() (restore func(), err error)
+}
+
+func (p prompter) prompt(opts []option) error {
+	for _, o := range opts {
+		if o.prompt == "" || o.sourceString() != sourceDefault || !o.isParentConfigured() {
+			continue
+		}
+
+		if err := p.promptOption(o); err != nil {
+			return fmt.Errorf("prompting %s failed: %w", promptName(o), err)
+		}
+	}
+	return nil
+}
+
+// promptOption asks the option value until a valid one is entered. An empty
+// answer keeps the default value, unless the option is required and its
+// default value is zero.
+func (p prompter) promptOption(o option) error {
+	values := optionEnumValues(o)
+
+	for {
+		var s string
+		var err error
+
+		switch {
+		case len(values) != 0:
+			s, err = p.readEnum(o, values)
+
+		case o.isSecret:
+			s, err = p.readSecret(o)
+
+		default:
+			s, err = p.readValue(o)
+		}
+		if err != nil {
+			return err
+		}
+
+		if s == "" {
+			if !o.isZero() || !isRequired(o) {
+				return nil
+			}
+			fmt.Fprintln(p.out, "A value is required.")
+			continue
+		}
+
+		if err := setPromptValue(o, s); err != nil {
+			fmt.Fprintf(p.out, "Invalid value: %s.\n", err)
+			continue
+		}
+		o.setSource(sourcePrompt, "")
+		return nil
+	}
+}
+
+func (p prompter) readValue(o option) (string, error) {
+	fmt.Fprint(p.out, o.prompt)
+	if !o.isZero() {
+		fmt.Fprintf(p.out, " [%s]", o.String())
+	}
+	fmt.Fprint(p.out, ": ")
+
+	return p.readLine()
+}
+
+// readSecret reads a value without echoing the typed characters. The default
+// value is never displayed.
+func (p prompter) readSecret(o option) (string, error) {
+	fmt.Fprintf(p.out, "%s: ", o.prompt)
+
+	restore, err := p.disableEcho()
+	if err != nil {
+		return "", err
+	}
+	defer fmt.Fprintln(p.out)
+	defer restore()
+
+	return p.readLine()
+}
+
+// readEnum displays the values an option accepts and reads either the number
+// or the value of a choice.
+func (p prompter) readEnum(o option, values []string) (string, error) {
+	fmt.Fprintf(p.out, "%s:\n", o.prompt)
+	for i, v := range values {
+		fmt.Fprintf(p.out, "    %d) %s\n", i+1, v)
+	}
+
+	fmt.Fprint(p.out, "Choice")
+	if !o.isZero() {
+		fmt.Fprintf(p.out, " [%s]", o.String())
+	}
+	fmt.Fprint(p.out, ": ")
+
+	s, err := p.readLine()
+	if err != nil {
+		return "", err
+	}
+
+	if i, err := strconv.Atoi(s); err == nil && i >= 1 && i <= len(values) {
+		return values[i-1], nil
+	}
+	return s, nil
+}
+
+// readLine reads the input until the end of the line. The input is read byte
+// by byte so that nothing after the line is consumed.
+func (p prompter) readLine() (string, error) {
+	var b strings.Builder
+	buf := make([]byte, 1)
+
+	for {
+		n, err := p.in.Read(buf)
+		if n != 0 {
+			if buf[0] == '\n' {
+				return strings.TrimSpace(b.String()), nil
+			}
+			b.WriteByte(buf[0])
+		}
+
+		if errors.Is(err, io.EOF) {
+			if b.Len() == 0 {
+				return "", io.ErrUnexpectedEOF
+			}
+			return strings.TrimSpace(b.String()), nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// setPromptValue sets the option with the given value and checks the option
+// validation rules. The previous value is restored when the value is invalid,
+// so that it can be prompted again.
+func setPromptValue(o option, s string) error {
+	prev := reflect.New(o.value.Type()).Elem()
+	prev.Set(o.value)
+
+	err := o.Set(s)
+	for _, r := range o.rules {
+		if err != nil {
+			break
+		}
+		err = r.validate(o)
+	}
+
+	if err != nil {
+		o.value.Set(prev)
+	}
+	return err
+}
+
+func promptName(o option) string {
+	if o.isArg {
+		return o.name
+	}
+	return "--" + o.name
+}
+
+func isRequired(o option) bool {
+	for _, r := range o.rules {
+		if r.name == "required" {
+			return true
+		}
+	}
+	return false
+}
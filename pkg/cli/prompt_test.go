@@ -0,0 +1,266 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrompter(t *testing.T) {
+	tests := []struct {
+		scenario string
+		opts     interface{}
+		input    string
+		isSet    bool
+		expected interface{}
+		output   string
+		sources  []string
+		err      bool
+	}{
+		{
+			scenario: "value is prompted",
+			opts: &struct {
+				URL  string `prompt:"Enter the database URL"`
+				Host string
+			}{},
+			input: "postgres://localhost\n",
+			expected: &struct {
+				URL  string `prompt:"Enter the database URL"`
+				Host string
+			}{
+				URL: "postgres://localhost",
+			},
+			output:  "Enter the database URL: ",
+			sources: []string{"prompt", "default"},
+		},
+		{
+			scenario: "empty answer keeps the default value",
+			opts: &struct {
+				Port int `prompt:"Enter the port"`
+			}{
+				Port: 8080,
+			},
+			input: "\n",
+			expected: &struct {
+				Port int `prompt:"Enter the port"`
+			}{
+				Port: 8080,
+			},
+			output:  "Enter the port [8080]: ",
+			sources: []string{"default"},
+		},
+		{
+			scenario: "invalid value is prompted again",
+			opts: &struct {
+				Port int `prompt:"Enter the port" validate:"max=65535"`
+			}{},
+			input: "abc\n70000\n443\n",
+			expected: &struct {
+				Port int `prompt:"Enter the port" validate:"max=65535"`
+			}{
+				Port: 443,
+			},
+			output: "" +
+				"Enter the port: Invalid value: invalid character 'a' looking for beginning of value.\n" +
+				"Enter the port: Invalid value: must be at most 65535.\n" +
+				"Enter the port: ",
+			sources: []string{"prompt"},
+		},
+		{
+			scenario: "required value is prompted again",
+			opts: &struct {
+				Name string `prompt:"Enter your name" validate:"required"`
+			}{},
+			input: "\nMaxence\n",
+			expected: &struct {
+				Name string `prompt:"Enter your name" validate:"required"`
+			}{
+				Name: "Maxence",
+			},
+			output:  "Enter your name: A value is required.\nEnter your name: ",
+			sources: []string{"prompt"},
+		},
+		{
+			scenario: "secret value is not displayed",
+			opts: &struct {
+				Password string `cli:"password,secret" prompt:"Enter the password"`
+			}{
+				Password: "default-password",
+			},
+			input: "p@ss\n",
+			expected: &struct {
+				Password string `cli:"password,secret" prompt:"Enter the password"`
+			}{
+				Password: "p@ss",
+			},
+			output:  "Enter the password: [echo disabled][echo restored]\n",
+			sources: []string{"prompt"},
+		},
+		{
+			scenario: "enum value is selected by number",
+			opts: &struct {
+				Level string `prompt:"Select the level" validate:"oneof=debug|info|error"`
+			}{
+				Level: "info",
+			},
+			input: "3\n",
+			expected: &struct {
+				Level string `prompt:"Select the level" validate:"oneof=debug|info|error"`
+			}{
+				Level: "error",
+			},
+			output: "" +
+				"Select the level:\n" +
+				"    1) debug\n" +
+				"    2) info\n" +
+				"    3) error\n" +
+				"Choice [info]: ",
+			sources: []string{"prompt"},
+		},
+		{
+			scenario: "enum value is selected by value",
+			opts: &struct {
+				Level string `prompt:"Select the level" validate:"oneof=debug|info"`
+			}{},
+			input: "trace\ndebug\n",
+			expected: &struct {
+				Level string `prompt:"Select the level" validate:"oneof=debug|info"`
+			}{
+				Level: "debug",
+			},
+			output: "" +
+				"Select the level:\n" +
+				"    1) debug\n" +
+				"    2) info\n" +
+				"Choice: Invalid value: must be one of debug, info: \"trace\" is not allowed.\n" +
+				"Select the level:\n" +
+				"    1) debug\n" +
+				"    2) info\n" +
+				"Choice: ",
+			sources: []string{"prompt"},
+		},
+		{
+			scenario: "options set by another source are not prompted",
+			opts: &struct {
+				Host string `prompt:"Enter the host"`
+			}{},
+			isSet:   true,
+			sources: []string{"flag:--host"},
+		},
+		{
+			scenario: "unanswered prompt returns an error",
+			opts: &struct {
+				Host string `prompt:"Enter the host"`
+			}{},
+			err: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			p := optionParser{
+				flags:     flag.NewFlagSet("test", flag.ContinueOnError),
+				lookupEnv: func(string) (string, bool) { return "", false },
+			}
+			opts, err := p.parse(test.opts)
+			require.NoError(t, err)
+
+			if test.isSet {
+				opts[0].setSource(sourceFlag, "--host")
+			}
+
+			out := bytes.NewBuffer(nil)
+			prompter := prompter{
+				in:  strings.NewReader(test.input),
+				out: out,
+				disableEcho: func() (func(), error) {
+					out.WriteString("[echo disabled]")
+					return func() { out.WriteString("[echo restored]") }, nil
+				},
+			}
+
+			err = prompter.prompt(opts)
+			if test.err {
+				require.Error(t, err)
+				t.Log("error:", err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.output, out.String())
+
+			if test.expected != nil {
+				require.Equal(t, test.expected, test.opts)
+			}
+
+			for i, source := range test.sources {
+				require.Equal(t, source, opts[i].sourceString())
+			}
+		})
+	}
+}
+
+func TestPrompterDisableEchoError(t *testing.T) {
+	p := optionParser{
+		flags:     flag.NewFlagSet("test", flag.ContinueOnError),
+		lookupEnv: func(string) (string, bool) { return "", false },
+	}
+	opts, err := p.parse(&struct {
+		Password string `cli:"password,secret" prompt:"Enter the password"`
+	}{})
+	require.NoError(t, err)
+
+	prompter := prompter{
+		in:  strings.NewReader("p@ss\n"),
+		out: writerNoop{},
+		disableEcho: func() (func(), error) {
+			return nil, errors.New("no terminal")
+		},
+	}
+
+	err = prompter.prompt(opts)
+	require.Error(t, err)
+	t.Log("error:", err)
+}
+
+func TestPromptOptions(t *testing.T) {
+	var opts struct {
+		Host string `prompt:"Enter the host"`
+	}
+
+	t.Run("options are not prompted when the input is not a terminal", func(t *testing.T) {
+		f, err := os.CreateTemp(t.TempDir(), "stdin")
+		require.NoError(t, err)
+		defer f.Close()
+
+		app := New("test")
+		app.Args = nil
+		app.Stdin = f
+		app.Stderr = writerNoop{}
+		app.Exit = func(code int) {
+			t.Fatalf("unexpected exit with code %v", code)
+		}
+		app.Register().Options(&opts)
+
+		app.Load()
+		require.Empty(t, opts.Host)
+	})
+
+	t.Run("non interactive flag is accepted", func(t *testing.T) {
+		app := New("test")
+		app.Args = []string{"--non-interactive", "--host", "localhost"}
+		app.Stdin = strings.NewReader("")
+		app.Stderr = writerNoop{}
+		app.Exit = func(code int) {
+			t.Fatalf("unexpected exit with code %v", code)
+		}
+		app.Register().Options(&opts)
+
+		app.Load()
+		require.Equal(t, "localhost", opts.Host)
+	})
+}
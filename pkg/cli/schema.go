@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"strings"
+)
+
+// commandSchema is the machine-readable description of a command that is
+// printed with the --help=json flag.
+type commandSchema struct {
+	Name       string          `json:"name"`
+	Usage      string          `json:"usage"`
+	Help       string          `json:"help,omitempty"`
+	Aliases    []string        `json:"aliases,omitempty"`
+	Group      string          `json:"group,omitempty"`
+	Deprecated string          `json:"deprecated,omitempty"`
+	Arguments  []optionSchema  `json:"arguments,omitempty"`
+	Options    []optionSchema  `json:"options,omitempty"`
+	Commands   []commandSchema `json:"commands,omitempty"`
+}
+
+type optionSchema struct {
+	Name       string      `json:"name"`
+	Short      string      `json:"short,omitempty"`
+	Type       string      `json:"type"`
+	Env        string      `json:"env,omitempty"`
+	Default    interface{} `json:"default,omitempty"`
+	Help       string      `json:"help,omitempty"`
+	Prompt     string      `json:"prompt,omitempty"`
+	Rules      []string    `json:"rules,omitempty"`
+	Values     []string    `json:"values,omitempty"`
+	Required   bool        `json:"required,omitempty"`
+	Repeatable bool        `json:"repeatable,omitempty"`
+	Secret     bool        `json:"secret,omitempty"`
+	Persistent bool        `json:"persistent,omitempty"`
+	Optional   bool        `json:"optional,omitempty"`
+}
+
+// commandSchemaUsage returns a usage function that writes the JSON schema of
+// the named command and of its subcommands.
+func commandSchemaUsage(w io.Writer, programName, name string, cmds map[string]Command) func() {
+	return func() {
+		docsCmds, err := docsCommands(cmds)
+		if err != nil {
+			printError(w, err)
+			return
+		}
+
+		nodes := make(map[string]docsCommand, len(docsCmds))
+		for _, c := range docsCmds {
+			nodes[c.name] = c
+		}
+
+		// Hidden commands are not part of the documented commands.
+		if c, ok := cmds[name]; ok && c.(*command).isHidden {
+			p := optionParser{persistent: persistentOptions(cmds, name)}
+			p.collect(c.(*command).options)
+			nodes[name] = docsCommand{
+				command: c.(*command),
+				name:    name,
+				help:    c.(*command).help,
+				options: p.options,
+			}
+		}
+
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(newCommandSchema(programName, nodes[name], nodes))
+	}
+}
+
+func newCommandSchema(programName string, c docsCommand, nodes map[string]docsCommand) commandSchema {
+	s := commandSchema{
+		Name:  commandString(programName, c.name),
+		Usage: docsUsage(programName, c),
+		Help:  schemaText(c.help),
+	}
+
+	if cmd := c.command; cmd != nil {
+		s.Aliases = cmd.aliases
+		s.Group = cmd.group
+		s.Deprecated = cmd.deprecated
+	}
+
+	for _, a := range commandArgs(c.options) {
+		s.Arguments = append(s.Arguments, newOptionSchema(a))
+	}
+
+	for _, o := range documentedOptions(c.options, false) {
+		s.Options = append(s.Options, newOptionSchema(o))
+	}
+
+	for _, o := range documentedOptions(c.options, true) {
+		s.Options = append(s.Options, newOptionSchema(o))
+	}
+
+	for _, sub := range c.subcommands {
+		s.Commands = append(s.Commands, newCommandSchema(programName, nodes[sub.name], nodes))
+	}
+	return s
+}
+
+func newOptionSchema(o option) optionSchema {
+	s := optionSchema{
+		Name:       o.name,
+		Short:      o.short,
+		Type:       optionTypeName(o),
+		Help:       schemaText(o.help),
+		Prompt:     o.prompt,
+		Values:     optionEnumValues(o),
+		Repeatable: o.isMultiValue() || o.argIndex == restArgIndex && o.isArg,
+		Secret:     o.isSecret,
+		Persistent: o.isPersistent,
+		Optional:   o.isOptionalGroup(),
+	}
+
+	if o.envKey != "-" {
+		s.Env = o.envKey
+	}
+
+	if !o.value.IsZero() {
+		s.Default = dumpValue(o)
+	}
+
+	for _, r := range o.rules {
+		s.Rules = append(s.Rules, r.String())
+		if r.name == "required" {
+			s.Required = true
+		}
+	}
+	return s
+}
+
+// helpFormat returns the format given to the help flag in the given args when
+// the help flag is not defined by the command options. eg. "--help=json".
+func helpFormat(flags *flag.FlagSet, args []string) string {
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+
+		name, format, ok := strings.Cut(strings.TrimPrefix(arg[1:], "-"), "=")
+		if ok && (name == "h" || name == "help") && (flags == nil || flags.Lookup(name) == nil) {
+			return format
+		}
+	}
+	return ""
+}
+
+func schemaText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
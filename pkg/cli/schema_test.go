@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommandSchemaUsage(t *testing.T) {
+	opts := struct {
+		Host    string   `short:"H" help:"The host."`
+		Level   string   `validate:"required,oneof=debug|info" prompt:"Select the level"`
+		Tags    []string `help:"The    tags."`
+		APIKey  string   `cli:"api-key,secret"`
+		Hidden  int      `cli:"hidden,hidden"`
+		Files   []string `arg:"rest"`
+		Verbose bool     `env:"-"`
+	}{
+		Host:   "localhost",
+		APIKey: "my-api-key",
+	}
+
+	m := commandManager{}
+	m.register().Help("A root command")
+	m.register("foo").Help("A test command").Options(&opts)
+	m.register("foo", "bar").Help("A nested command")
+
+	w := bytes.NewBuffer(nil)
+	commandSchemaUsage(w, "test", "foo", m.commands)()
+	require.NotContains(t, w.String(), "my-api-key")
+	t.Log(w.String())
+
+	var schema commandSchema
+	err := json.Unmarshal(w.Bytes(), &schema)
+	require.NoError(t, err)
+
+	require.Equal(t, "test foo", schema.Name)
+	require.Equal(t, "test foo [options] [files...] <command>", schema.Usage)
+	require.Equal(t, "A test command", schema.Help)
+	require.Equal(t, []optionSchema{
+		{Name: "files", Type: "[]string", Repeatable: true},
+	}, schema.Arguments)
+	require.Equal(t, []optionSchema{
+		{Name: "host", Short: "H", Type: "string", Env: "HOST", Default: "localhost", Help: "The host."},
+		{Name: "level", Type: "string", Env: "LEVEL", Rules: []string{"required", "oneof=debug|info"}, Values: []string{"debug", "info"}, Required: true, Prompt: "Select the level"},
+		{Name: "tags", Type: "[]string", Env: "TAGS", Help: "The tags.", Repeatable: true},
+		{Name: "api-key", Type: "string", Env: "API_KEY", Default: redactedValue, Secret: true},
+		{Name: "verbose", Type: "bool"},
+		{Name: "config", Type: "string", Help: "The path of a JSON, YAML or TOML file to load options from."},
+	}, schema.Options)
+	require.Len(t, schema.Commands, 1)
+	require.Equal(t, "test foo bar", schema.Commands[0].Name)
+}
+
+func TestHelpJSON(t *testing.T) {
+	tests := []struct {
+		scenario string
+		args     []string
+		expected string
+	}{
+		{
+			scenario: "index",
+			args:     []string{"--help=json"},
+			expected: "test",
+		},
+		{
+			scenario: "command",
+			args:     []string{"foo", "-h=json"},
+			expected: "test foo",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			stdout := bytes.NewBuffer(nil)
+			stderr := bytes.NewBuffer(nil)
+
+			var code int
+			app := New("test")
+			app.Args = test.args
+			app.Stdout = stdout
+			app.Stderr = stderr
+			app.Exit = func(c int) { code = c }
+			app.Register("foo").Help("A test command")
+
+			app.Load()
+			require.Equal(t, -1, code)
+			require.Empty(t, stderr.String())
+
+			var schema commandSchema
+			err := json.Unmarshal(stdout.Bytes(), &schema)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, schema.Name)
+			t.Log(stdout.String())
+		})
+	}
+}
+
+func TestHelpFormat(t *testing.T) {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	require.Equal(t, "json", helpFormat(flags, []string{"-v", "--help=json"}))
+	require.Equal(t, "json", helpFormat(nil, []string{"-h=json"}))
+	require.Empty(t, helpFormat(flags, []string{"--help"}))
+
+	flags.Bool("h", false, "")
+	require.Empty(t, helpFormat(flags, []string{"-h=json"}))
+}
@@ -0,0 +1,92 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	redactedValue       = "******"
+	secretFileEnvSuffix = "_FILE"
+)
+
+// redact returns the given value, or a mask when the option is a secret.
+func (o option) redact(s string) string {
+	if o.isSecret {
+		return redactedValue
+	}
+	return s
+}
+
+// secretFileEnvKey returns the environment variable that names the file
+// containing the value of a secret option.
+func (o option) secretFileEnvKey() string {
+	return o.envKey + secretFileEnvSuffix
+}
+
+// envKeys returns the environment variables that set the option, separated by
+// commas.
+func (o option) envKeys() string {
+	if o.isSecret {
+		return o.envKey + ", " + o.secretFileEnvKey()
+	}
+	return o.envKey
+}
+
+// loadSecretFile sets a secret option with the content of the file named in
+// its <ENV>_FILE variable of the given source, following the Docker and
+// Kubernetes secrets convention. Trailing new lines are trimmed.
+func (o option) loadSecretFile(s Source) error {
+	fileKey := o.secretFileEnvKey()
+
+	file, ok, err := s.Lookup("", fileKey)
+	if err != nil {
+		return err
+	}
+	if !ok || file.Value == "" {
+		return nil
+	}
+
+	if _, ok, err := s.Lookup("", o.envKey); err != nil {
+		return err
+	} else if ok {
+		return fmt.Errorf("%s and %s are both set", o.envKey, fileKey)
+	}
+
+	b, err := os.ReadFile(file.Value)
+	if err != nil {
+		return fmt.Errorf("reading %s file failed: %w", fileKey, err)
+	}
+
+	if err := o.Set(strings.TrimRight(string(b), "\r\n")); err != nil {
+		return fmt.Errorf("setting %q from %s file failed", o.name, fileKey)
+	}
+
+	o.setSource(file.Kind, file.Key)
+	return nil
+}
+
+// redactError returns the given error of a flag value. The error of a secret
+// option is replaced by one that does not contain the value, and is kept to
+// replace the flag parsing error.
+func (f *flagValue) redactError(name string, err error) error {
+	if err == nil || !f.isSecret {
+		return err
+	}
+
+	f.secretErr = fmt.Errorf("invalid value for flag %s", name)
+	return f.secretErr
+}
+
+// redactFlagsError returns the error of the secret flag value that failed to
+// be set, or the given flag parsing error.
+func redactFlagsError(flags *flag.FlagSet, err error) error {
+	flags.VisitAll(func(f *flag.Flag) {
+		if v, ok := f.Value.(*flagValue); ok && v.secretErr != nil {
+			err = v.secretErr
+		}
+	})
+	return err
+}
@@ -0,0 +1,197 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type secretOptions struct {
+	APIKey   string `cli:"api-key,secret" validate:"oneof=foo|bar"`
+	Password string `cli:"password,hidden,secret"`
+	Port     int    `cli:",secret"`
+}
+
+func TestSecretOptions(t *testing.T) {
+	dir := t.TempDir()
+
+	apiKeyFile := filepath.Join(dir, "api-key")
+	err := os.WriteFile(apiKeyFile, []byte("foo\n"), 0600)
+	require.NoError(t, err)
+
+	portFile := filepath.Join(dir, "port")
+	err = os.WriteFile(portFile, []byte("not-a-port"), 0600)
+	require.NoError(t, err)
+
+	tests := []struct {
+		scenario string
+		env      map[string]string
+		args     []string
+		expected secretOptions
+		err      bool
+	}{
+		{
+			scenario: "value from file",
+			env: map[string]string{
+				"API_KEY_FILE": apiKeyFile,
+			},
+			expected: secretOptions{APIKey: "foo"},
+		},
+		{
+			scenario: "value from env",
+			env: map[string]string{
+				"PASSWORD": "secret",
+			},
+			expected: secretOptions{Password: "secret"},
+		},
+		{
+			scenario: "flag overrides value from file",
+			env: map[string]string{
+				"API_KEY_FILE": apiKeyFile,
+			},
+			args:     []string{"-api-key", "bar"},
+			expected: secretOptions{APIKey: "bar"},
+		},
+		{
+			scenario: "empty file variable is ignored",
+			env: map[string]string{
+				"API_KEY_FILE": "",
+			},
+		},
+		{
+			scenario: "env and file variables both set returns an error",
+			env: map[string]string{
+				"API_KEY":      "foo",
+				"API_KEY_FILE": apiKeyFile,
+			},
+			err: true,
+		},
+		{
+			scenario: "missing file returns an error",
+			env: map[string]string{
+				"API_KEY_FILE": filepath.Join(dir, "missing"),
+			},
+			err: true,
+		},
+		{
+			scenario: "invalid file content returns an error",
+			env: map[string]string{
+				"PORT_FILE": portFile,
+			},
+			err: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			var opts secretOptions
+
+			p := optionParser{
+				flags: flag.NewFlagSet("test", flag.ContinueOnError),
+				lookupEnv: func(k string) (string, bool) {
+					v, ok := test.env[k]
+					return v, ok
+				},
+			}
+			p.flags.SetOutput(writerNoop{})
+
+			_, err := p.parse(&opts)
+			if test.err {
+				require.Error(t, err)
+				require.NotContains(t, err.Error(), "not-a-port")
+				t.Log("error:", err)
+				return
+			}
+			require.NoError(t, err)
+
+			err = p.flags.Parse(test.args)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, opts)
+		})
+	}
+}
+
+func TestSecretOptionsRedaction(t *testing.T) {
+	opts := secretOptions{
+		APIKey: "my-api-key",
+		Port:   4242,
+	}
+
+	var p optionParser
+	err := p.collect(&opts)
+	require.NoError(t, err)
+
+	w := bytes.NewBuffer(nil)
+	commandUsage(w, defaultUsageWidth, "test", &command{}, p.options, nil)()
+	require.NotContains(t, w.String(), "my-api-key")
+	require.NotContains(t, w.String(), "4242")
+	require.Contains(t, w.String(), "API_KEY_FILE")
+	require.Contains(t, w.String(), redactedValue)
+	t.Log(w.String())
+
+	err = validateOptions(p.options)
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "my-api-key")
+	t.Log("error:", err)
+}
+
+func TestSecretFlagRedaction(t *testing.T) {
+	tests := []struct {
+		scenario string
+		args     []string
+	}{
+		{
+			scenario: "invalid flag value",
+			args:     []string{"--token", "abc123"},
+		},
+		{
+			scenario: "invalid short flag value",
+			args:     []string{"-t=abc123"},
+		},
+		{
+			scenario: "invalid deprecated flag value",
+			args:     []string{"--auth-token", "abc123"},
+		},
+		{
+			scenario: "invalid slice flag value",
+			args:     []string{"--pins", "1", "--pins", "abc123"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			var opts struct {
+				Token int   `cli:",secret" short:"t" deprecated:"--auth-token"`
+				Pins  []int `cli:",secret"`
+			}
+
+			w := bytes.NewBuffer(nil)
+			exited := false
+
+			app := New("test")
+			app.Args = test.args
+			app.Stderr = w
+			app.Exit = func(code int) { exited = true }
+			app.Register().Options(&opts)
+
+			app.Load()
+			require.True(t, exited)
+			require.Contains(t, w.String(), "invalid value for flag")
+			require.NotContains(t, w.String(), "abc123")
+			t.Log(w.String())
+		})
+	}
+}
+
+func TestOptionRedact(t *testing.T) {
+	o := option{value: reflect.ValueOf("value")}
+	require.Equal(t, "value", o.redact("value"))
+
+	o.isSecret = true
+	require.Equal(t, redactedValue, o.redact("value"))
+}
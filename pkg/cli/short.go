@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// checkShorts ensures that short aliases are single characters that do not
+// collide with other option names or aliases.
+func checkShorts(opts []option) error {
+	names := make(map[string]string, len(opts))
+	for _, o := range opts {
+		names[o.name] = o.name
+	}
+
+	for _, o := range opts {
+		if o.short == "" {
+			continue
+		}
+
+		if o.isArg {
+			return fmt.Errorf("argument %q cannot have a short alias", o.name)
+		}
+
+		if utf8.RuneCountInString(o.short) != 1 || o.short == "-" {
+			return fmt.Errorf("option %q has an invalid short alias: %q", o.name, o.short)
+		}
+
+		if name, ok := names[o.short]; ok {
+			return fmt.Errorf("option %q short alias %q collides with option %q", o.name, o.short, name)
+		}
+		names[o.short] = o.name
+	}
+
+	return nil
+}
+
+// expandShortFlags expands the combined short flags from the given args into
+// separate flags. eg. "-vq" is expanded to "-v -q" and "-vofile" to "-v -o
+// file". Arguments that are defined flags or that contain undefined short flags
+// are left untouched.
+func expandShortFlags(flags *flag.FlagSet, args []string) []string {
+	expanded := make([]string, 0, len(args))
+	expectsValue := false
+
+	for i, arg := range args {
+		if expectsValue {
+			expanded = append(expanded, arg)
+			expectsValue = false
+			continue
+		}
+
+		if arg == "--" {
+			return append(expanded, args[i:]...)
+		}
+
+		if !strings.HasPrefix(arg, "-") || len(arg) < 2 || strings.Contains(arg, "=") {
+			expanded = append(expanded, arg)
+			continue
+		}
+
+		name := strings.TrimPrefix(arg[1:], "-")
+		if f := flags.Lookup(name); f != nil {
+			expanded = append(expanded, arg)
+			expectsValue = !isBoolFlag(f)
+			continue
+		}
+
+		if strings.HasPrefix(arg, "--") {
+			expanded = append(expanded, arg)
+			continue
+		}
+
+		shorts, expectsVal, ok := splitShortFlags(flags, name)
+		if !ok {
+			expanded = append(expanded, arg)
+			continue
+		}
+		expanded = append(expanded, shorts...)
+		expectsValue = expectsVal
+	}
+
+	return expanded
+}
+
+func splitShortFlags(flags *flag.FlagSet, name string) (shorts []string, expectsValue, ok bool) {
+	for i, r := range name {
+		f := flags.Lookup(string(r))
+		if f == nil {
+			return nil, false, false
+		}
+		shorts = append(shorts, "-"+string(r))
+
+		if isBoolFlag(f) {
+			continue
+		}
+
+		if value := name[i+utf8.RuneLen(r):]; value != "" {
+			return append(shorts, value), false, true
+		}
+		return shorts, true, true
+	}
+
+	return shorts, false, true
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
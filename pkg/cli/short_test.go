@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckShorts(t *testing.T) {
+	tests := []struct {
+		scenario string
+		options  interface{}
+		err      bool
+	}{
+		{
+			scenario: "valid short aliases",
+			options: &struct {
+				Verbose bool   `short:"v"`
+				Output  string `short:"o"`
+			}{},
+		},
+		{
+			scenario: "multiple characters returns an error",
+			options: &struct {
+				Verbose bool `short:"vv"`
+			}{},
+			err: true,
+		},
+		{
+			scenario: "dash returns an error",
+			options: &struct {
+				Verbose bool `short:"-"`
+			}{},
+			err: true,
+		},
+		{
+			scenario: "duplicate short aliases returns an error",
+			options: &struct {
+				Verbose bool `short:"v"`
+				Version bool `short:"v"`
+			}{},
+			err: true,
+		},
+		{
+			scenario: "short alias colliding with an option name returns an error",
+			options: &struct {
+				Verbose bool `short:"v"`
+				V       bool
+			}{},
+			err: true,
+		},
+		{
+			scenario: "short alias on an argument returns an error",
+			options: &struct {
+				File string `arg:"0" short:"f"`
+			}{},
+			err: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			var p optionParser
+			err := p.collect(test.options)
+			if test.err {
+				require.Error(t, err)
+				t.Log("error:", err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestExpandShortFlags(t *testing.T) {
+	tests := []struct {
+		scenario string
+		args     []string
+		expected []string
+	}{
+		{
+			scenario: "combined bool flags",
+			args:     []string{"-vq"},
+			expected: []string{"-v", "-q"},
+		},
+		{
+			scenario: "combined flags with attached value",
+			args:     []string{"-vqnfoo"},
+			expected: []string{"-v", "-q", "-n", "foo"},
+		},
+		{
+			scenario: "combined flags with value",
+			args:     []string{"-vn", "-vq"},
+			expected: []string{"-v", "-n", "-vq"},
+		},
+		{
+			scenario: "defined flag",
+			args:     []string{"-name", "-vq", "--name", "-vq"},
+			expected: []string{"-name", "-vq", "--name", "-vq"},
+		},
+		{
+			scenario: "undefined flag",
+			args:     []string{"-vx", "--vq"},
+			expected: []string{"-vx", "--vq"},
+		},
+		{
+			scenario: "flag with equal sign",
+			args:     []string{"-n=vq"},
+			expected: []string{"-n=vq"},
+		},
+		{
+			scenario: "end of options",
+			args:     []string{"-vq", "--", "-vq"},
+			expected: []string{"-v", "-q", "--", "-vq"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			var opts struct {
+				Verbose bool   `short:"v"`
+				Quiet   bool   `short:"q"`
+				Name    string `short:"n"`
+			}
+
+			p := optionParser{flags: flag.NewFlagSet("test", flag.ContinueOnError)}
+			_, err := p.parse(&opts)
+			require.NoError(t, err)
+
+			args := expandShortFlags(p.flags, test.args)
+			require.Equal(t, test.expected, args)
+		})
+	}
+}
+
+func TestCommandManagerParseShortFlags(t *testing.T) {
+	var opts struct {
+		Verbose bool   `short:"v"`
+		Quiet   bool   `short:"q"`
+		Name    string `short:"n"`
+	}
+
+	m := commandManager{}
+	m.register().Options(&opts)
+
+	_, _, err := m.parse("-vq", "-n", "foo")
+	require.NoError(t, err)
+	require.True(t, opts.Verbose)
+	require.True(t, opts.Quiet)
+	require.Equal(t, "foo", opts.Name)
+}
@@ -0,0 +1,313 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Source is a source of option values, such as the environment, dotenv files
+// or a secrets directory.
+type Source interface {
+	// Lookup returns the value of the option with the given name and
+	// environment variable key. ok reports whether the source has a value for
+	// the option. The name is empty when only the environment variable key is
+	// looked up, such as for deprecated or <ENV>_FILE variables.
+	Lookup(name, envKey string) (v SourceValue, ok bool, err error)
+}
+
+// SourceFunc is a function that implements the Source interface.
+type SourceFunc func(name, envKey string) (SourceValue, bool, error)
+
+// Lookup calls f(name, envKey).
+func (f SourceFunc) Lookup(name, envKey string) (SourceValue, bool, error) {
+	return f(name, envKey)
+}
+
+// SourceValue is an option value found in a source.
+type SourceValue struct {
+	// The value, parsed as an environment variable value.
+	Value string
+
+	// The kind of the source where the value is found. eg. "env" or "file".
+	Kind string
+
+	// The key of the value in the source. eg. an environment variable name or
+	// a file path.
+	Key string
+}
+
+// SetSources sets the sources of the default app. See App.Sources.
+func SetSources(sources ...Source) {
+	defaultApp.Sources = sources
+}
+
+// EnvSource returns a source that looks up option values in environment
+// variables with the given function. os.LookupEnv is used when the function is
+// nil.
+func EnvSource(lookupEnv func(string) (string, bool)) Source {
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+
+	return SourceFunc(func(name, envKey string) (SourceValue, bool, error) {
+		v, ok := lookupEnv(envKey)
+		return SourceValue{Value: v, Kind: sourceEnv, Key: envKey}, ok, nil
+	})
+}
+
+// DotenvSource returns a source that looks up option values in the variables
+// of the given dotenv files. Variables of a file override the ones of the
+// previous files. Missing files are ignored. Variables referenced in values
+// are expanded with the environment and the variables previously defined.
+func DotenvSource(filenames ...string) Source {
+	return newDotenvSource(os.LookupEnv, filenames)
+}
+
+// FileSource returns a source that looks up option values in the files of the
+// given directory, such as mounted Docker or Kubernetes secrets. A value is
+// read from the file named after the option environment variable, or after the
+// option name. Trailing new lines are trimmed.
+func FileSource(dir string) Source {
+	return SourceFunc(func(name, envKey string) (SourceValue, bool, error) {
+		for _, k := range []string{envKey, name} {
+			if k == "" {
+				continue
+			}
+
+			filename := filepath.Join(dir, k)
+			b, err := os.ReadFile(filename)
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return SourceValue{}, false, fmt.Errorf("reading %s file failed: %w", k, err)
+			}
+
+			return SourceValue{
+				Value: strings.TrimRight(string(b), "\r\n"),
+				Kind:  sourceFile,
+				Key:   filename,
+			}, true, nil
+		}
+		return SourceValue{}, false, nil
+	})
+}
+
+// FlagSource returns a source that looks up option values in the given flag
+// arguments. eg. []string{"--host", "localhost", "-v", "--port=8080"}. Bool
+// flags, short aliases and combined short flags are resolved with the loaded
+// options, and arguments that are not flags are ignored.
+func FlagSource(args []string) Source {
+	return flagSource{args: args}
+}
+
+type flagSource struct {
+	args []string
+}
+
+// Lookup returns the value of the flag with the given name. Since the option
+// type is unknown, the flag always takes a value.
+func (s flagSource) Lookup(name, envKey string) (SourceValue, bool, error) {
+	if name == "" {
+		return SourceValue{}, false, nil
+	}
+
+	v, ok := lookupFlagValue(s.args, name)
+	return SourceValue{Value: v, Kind: sourceFlag, Key: flagName(name)}, ok, nil
+}
+
+func (s flagSource) bind(opts []option) Source {
+	flags := flag.NewFlagSet("", flag.ContinueOnError)
+	for _, o := range opts {
+		if o.isArg {
+			continue
+		}
+
+		flags.Var(o, o.name, "")
+		if o.short != "" {
+			flags.Var(o, o.short, "")
+		}
+	}
+
+	values := make(map[string]SourceValue)
+	args := expandShortFlags(flags, s.args)
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(arg[1:], "-"), "=")
+		f := flags.Lookup(name)
+		if f == nil {
+			continue
+		}
+
+		if !hasValue {
+			switch {
+			case isBoolFlag(f):
+				value = "true"
+
+			case i+1 < len(args):
+				i++
+				value = args[i]
+
+			default:
+				continue
+			}
+		}
+
+		values[f.Value.(option).name] = SourceValue{
+			Value: value,
+			Kind:  sourceFlag,
+			Key:   flagName(name),
+		}
+	}
+
+	return SourceFunc(func(name, envKey string) (SourceValue, bool, error) {
+		v, ok := values[name]
+		return v, ok && name != "", nil
+	})
+}
+
+// optionsBinder is implemented by sources that need the loaded options to look
+// up values, such as a flag source that needs to know which flags are bool.
+type optionsBinder interface {
+	// bind returns the source that looks up the values of the given options.
+	bind(opts []option) Source
+}
+
+// bindSources returns the given sources, bound to the given options when they
+// implement optionsBinder.
+func bindSources(sources []Source, opts []option) []Source {
+	bound := make([]Source, len(sources))
+	for i, s := range sources {
+		if b, ok := s.(optionsBinder); ok {
+			s = b.bind(opts)
+		}
+		bound[i] = s
+	}
+	return bound
+}
+
+type dotenvSource struct {
+	filenames []string
+	lookupEnv func(string) (string, bool)
+
+	once    sync.Once
+	vars    map[string]string
+	origins map[string]string
+	err     error
+}
+
+func newDotenvSource(lookupEnv func(string) (string, bool), filenames []string) *dotenvSource {
+	return &dotenvSource{
+		filenames: filenames,
+		lookupEnv: lookupEnv,
+	}
+}
+
+func (s *dotenvSource) Lookup(name, envKey string) (SourceValue, bool, error) {
+	s.once.Do(s.load)
+	if s.err != nil {
+		return SourceValue{}, false, s.err
+	}
+
+	v, ok := s.vars[envKey]
+	return SourceValue{
+		Value: v,
+		Kind:  sourceDotenv,
+		Key:   s.origins[envKey] + ":" + envKey,
+	}, ok, nil
+}
+
+// load reads the dotenv files. Variables referenced in values are expanded
+// with the environment first, then with the variables of the current file and
+// of the previous files.
+func (s *dotenvSource) load() {
+	s.vars = make(map[string]string)
+	s.origins = make(map[string]string)
+
+	for _, filename := range s.filenames {
+		b, err := os.ReadFile(filename)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			s.err = fmt.Errorf("reading env file failed: %w", err)
+			return
+		}
+
+		vars := make(map[string]string)
+		lookup := func(k string) (string, bool) {
+			if v, ok := s.lookupEnv(k); ok {
+				return v, true
+			}
+			if v, ok := vars[k]; ok {
+				return v, true
+			}
+			v, ok := s.vars[k]
+			return v, ok
+		}
+
+		if err := parseDotenv(string(b), vars, lookup); err != nil {
+			s.err = fmt.Errorf("parsing env file %q failed: %w", filename, err)
+			return
+		}
+
+		for k, v := range vars {
+			s.vars[k] = v
+			s.origins[k] = filename
+		}
+	}
+}
+
+// loadSource sets the option with the value found in the given source, and
+// records the value provenance. Values of deprecated environment variables are
+// loaded first so that the current one overrides them.
+func (o option) loadSource(s Source) error {
+	if o.isSecret {
+		if err := o.loadSecretFile(s); err != nil {
+			return err
+		}
+	}
+
+	if err := o.loadDeprecatedEnv(s); err != nil {
+		return err
+	}
+
+	v, ok, err := s.Lookup(o.name, o.envKey)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+	return o.setSourceValue(v)
+}
+
+// setSourceValue sets the option with the given source value and records the
+// value provenance. Errors name the source key, and do not contain the value
+// of secret options.
+func (o option) setSourceValue(v SourceValue) error {
+	if err := o.Set(v.Value); err != nil {
+		source := valueSource{kind: v.Kind, key: v.Key}
+		if o.isSecret {
+			return fmt.Errorf("setting %q from %s failed", o.name, source)
+		}
+		return fmt.Errorf("setting %q from %s failed: %w", o.name, source, err)
+	}
+
+	o.setSource(v.Kind, v.Key)
+	return nil
+}
@@ -0,0 +1,336 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvSource(t *testing.T) {
+	s := EnvSource(func(k string) (string, bool) {
+		return "localhost", k == "HOST"
+	})
+
+	v, ok, err := s.Lookup("host", "HOST")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, SourceValue{Value: "localhost", Kind: "env", Key: "HOST"}, v)
+
+	_, ok, err = s.Lookup("port", "PORT")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestFileSource(t *testing.T) {
+	dir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(dir, "API_KEY"), []byte("secret\n"), 0600)
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(dir, "host"), []byte("localhost"), 0600)
+	require.NoError(t, err)
+
+	err = os.Mkdir(filepath.Join(dir, "PORT"), 0700)
+	require.NoError(t, err)
+
+	tests := []struct {
+		scenario string
+		name     string
+		envKey   string
+		expected SourceValue
+		ok       bool
+		err      bool
+	}{
+		{
+			scenario: "file named after the env key",
+			name:     "api-key",
+			envKey:   "API_KEY",
+			expected: SourceValue{Value: "secret", Kind: "file", Key: filepath.Join(dir, "API_KEY")},
+			ok:       true,
+		},
+		{
+			scenario: "file named after the option name",
+			name:     "host",
+			envKey:   "HOST",
+			expected: SourceValue{Value: "localhost", Kind: "file", Key: filepath.Join(dir, "host")},
+			ok:       true,
+		},
+		{
+			scenario: "env key only",
+			envKey:   "HOST",
+		},
+		{
+			scenario: "missing file",
+			name:     "level",
+			envKey:   "LEVEL",
+		},
+		{
+			scenario: "unreadable file returns an error",
+			name:     "port",
+			envKey:   "PORT",
+			err:      true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			v, ok, err := FileSource(dir).Lookup(test.name, test.envKey)
+			if test.err {
+				require.Error(t, err)
+				t.Log("error:", err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.ok, ok)
+			require.Equal(t, test.expected, v)
+		})
+	}
+}
+
+func TestFlagSource(t *testing.T) {
+	s := FlagSource([]string{"--host", "localhost", "-v", "--port=8080"})
+
+	v, ok, err := s.Lookup("port", "PORT")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, SourceValue{Value: "8080", Kind: "flag", Key: "--port"}, v)
+
+	_, ok, err = s.Lookup("", "HOST")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestFlagSourceOptions(t *testing.T) {
+	type options struct {
+		Verbose bool   `short:"v"`
+		Quiet   bool   `short:"q"`
+		Output  string `short:"o"`
+		Port    int
+		Name    string `cli:"name,arg"`
+	}
+
+	tests := []struct {
+		scenario string
+		args     []string
+		expected options
+		sources  map[string]string
+	}{
+		{
+			scenario: "bool flag followed by a positional argument",
+			args:     []string{"--verbose", "sub", "--port", "8080"},
+			expected: options{Verbose: true, Port: 8080},
+			sources: map[string]string{
+				"verbose": "flag:--verbose",
+				"port":    "flag:--port",
+			},
+		},
+		{
+			scenario: "bool flag with a value",
+			args:     []string{"--verbose=false", "-q=true"},
+			expected: options{Quiet: true},
+			sources: map[string]string{
+				"verbose": "flag:--verbose",
+				"quiet":   "flag:-q",
+			},
+		},
+		{
+			scenario: "short aliases",
+			args:     []string{"-v", "-o", "out.txt"},
+			expected: options{Verbose: true, Output: "out.txt"},
+			sources: map[string]string{
+				"verbose": "flag:-v",
+				"output":  "flag:-o",
+			},
+		},
+		{
+			scenario: "combined short flags",
+			args:     []string{"-vqo", "out.txt"},
+			expected: options{Verbose: true, Quiet: true, Output: "out.txt"},
+			sources: map[string]string{
+				"verbose": "flag:-v",
+				"quiet":   "flag:-q",
+				"output":  "flag:-o",
+			},
+		},
+		{
+			scenario: "unknown flags and arguments are ignored",
+			args:     []string{"--unknown", "--port", "8080", "bob", "--", "--quiet"},
+			expected: options{Port: 8080},
+			sources: map[string]string{
+				"port":  "flag:--port",
+				"quiet": "default",
+				"name":  "default",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			var opts options
+
+			p := optionParser{
+				flags:   flag.NewFlagSet("test", flag.ContinueOnError),
+				sources: []Source{FlagSource(test.args)},
+			}
+
+			loaded, err := p.parse(&opts)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, opts)
+
+			for _, o := range loaded {
+				if source, ok := test.sources[o.name]; ok {
+					require.Equal(t, source, o.sourceString())
+				}
+			}
+		})
+	}
+}
+
+func TestSources(t *testing.T) {
+	dir := t.TempDir()
+
+	secretsDir := filepath.Join(dir, "secrets")
+	err := os.Mkdir(secretsDir, 0700)
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(secretsDir, "API_KEY"), []byte("file-key"), 0600)
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(secretsDir, "HOST"), []byte("file-host"), 0600)
+	require.NoError(t, err)
+
+	envFile := filepath.Join(dir, ".env")
+	err = os.WriteFile(envFile, []byte("HOST=dotenv-host\nPORT=8080\n"), 0600)
+	require.NoError(t, err)
+
+	var opts struct {
+		Host   string
+		Port   int
+		Level  string
+		APIKey string `cli:"api-key,secret"`
+		URL    string `deprecated:"ENDPOINT"`
+	}
+
+	custom := SourceFunc(func(name, envKey string) (SourceValue, bool, error) {
+		switch envKey {
+		case "LEVEL":
+			return SourceValue{Value: "debug", Kind: "stdin", Key: "level"}, true, nil
+
+		case "ENDPOINT":
+			return SourceValue{Value: "http://old", Kind: "stdin", Key: "endpoint"}, true, nil
+		}
+		return SourceValue{}, false, nil
+	})
+
+	app := New("test")
+	app.Args = []string{"--port", "9090"}
+	app.Stderr = writerNoop{}
+	app.Exit = func(code int) {
+		t.Fatalf("unexpected exit with code %v", code)
+	}
+	app.Sources = []Source{
+		FileSource(secretsDir),
+		newDotenvSource(func(string) (string, bool) { return "", false }, []string{envFile}),
+		custom,
+		EnvSource(func(k string) (string, bool) {
+			return "env-level", k == "LEVEL"
+		}),
+	}
+	app.Register().Options(&opts)
+	app.Load()
+
+	require.Equal(t, "dotenv-host", opts.Host)
+	require.Equal(t, 9090, opts.Port)
+	require.Equal(t, "env-level", opts.Level)
+	require.Equal(t, "file-key", opts.APIKey)
+	require.Equal(t, "http://old", opts.URL)
+
+	w := bytes.NewBuffer(nil)
+	err = app.Dump(w, Env)
+	require.NoError(t, err)
+	require.Equal(t, ""+
+		"HOST=dotenv-host # dotenv:"+envFile+":HOST\n"+
+		"PORT=9090 # flag:--port\n"+
+		"LEVEL=env-level # env:LEVEL\n"+
+		"API_KEY=****** # file:"+filepath.Join(secretsDir, "API_KEY")+"\n"+
+		"URL=http://old # stdin:endpoint\n",
+		w.String(),
+	)
+}
+
+func TestSourcesError(t *testing.T) {
+	code := 0
+
+	app := New("test")
+	app.Args = nil
+	app.Stderr = writerNoop{}
+	app.Exit = func(c int) { code = c }
+	app.Sources = []Source{
+		SourceFunc(func(name, envKey string) (SourceValue, bool, error) {
+			return SourceValue{}, false, errors.New("source error")
+		}),
+	}
+	app.Register().Options(&struct {
+		Host string
+	}{})
+
+	app.Load()
+	require.Equal(t, -1, code)
+}
+
+func TestSourcesInvalidValue(t *testing.T) {
+	tests := []struct {
+		scenario string
+		env      map[string]string
+		opts     interface{}
+		contains string
+	}{
+		{
+			scenario: "invalid value",
+			env:      map[string]string{"PORT": "abc"},
+			opts: &struct {
+				Port int `validate:"min=1"`
+			}{},
+			contains: `setting "port" from env:PORT failed`,
+		},
+		{
+			scenario: "invalid deprecated value",
+			env:      map[string]string{"OLD_PORT": "abc"},
+			opts: &struct {
+				Port int `deprecated:"OLD_PORT"`
+			}{},
+			contains: `setting "port" from env:OLD_PORT failed`,
+		},
+		{
+			scenario: "invalid secret value is not displayed",
+			env:      map[string]string{"PIN": "my-secret-pin"},
+			opts: &struct {
+				PIN int `cli:"pin,secret"`
+			}{},
+			contains: `setting "pin" from env:PIN failed`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			p := optionParser{
+				flags: flag.NewFlagSet("test", flag.ContinueOnError),
+				lookupEnv: func(k string) (string, bool) {
+					v, ok := test.env[k]
+					return v, ok
+				},
+			}
+
+			_, err := p.parse(test.opts)
+			require.Error(t, err)
+			require.ErrorContains(t, err, test.contains)
+			require.NotContains(t, err.Error(), "my-secret-pin")
+			t.Log("error:", err)
+		})
+	}
+}
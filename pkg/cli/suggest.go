@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	maxSuggestions = 3
+)
+
+// suggest returns the candidates that are the closest to the given name,
+// sorted by edit distance.
+func suggest(name string, candidates []string) []string {
+	type suggestion struct {
+		value    string
+		distance int
+	}
+
+	maxDistance := len(name)/4 + 1
+
+	var res []suggestion
+	for _, c := range candidates {
+		if c == "" || c == name {
+			continue
+		}
+
+		d := editDistance(name, c)
+		if d <= maxDistance || len(name) > 1 && strings.HasPrefix(c, name) {
+			res = append(res, suggestion{value: c, distance: d})
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].distance != res[j].distance {
+			return res[i].distance < res[j].distance
+		}
+		return res[i].value < res[j].value
+	})
+
+	suggestions := make([]string, 0, maxSuggestions)
+	for i := 0; i < len(res) && i < maxSuggestions; i++ {
+		suggestions = append(suggestions, res[i].value)
+	}
+	return suggestions
+}
+
+// editDistance returns the optimal string alignment distance between the
+// given strings: the number of insertions, deletions, substitutions and
+// transpositions of adjacent characters needed to change a into b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			d[i][j] = min(
+				d[i-1][j]+1,
+				d[i][j-1]+1,
+				d[i-1][j-1]+cost,
+			)
+
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+
+	return d[len(ra)][len(rb)]
+}
+
+// withSuggestions appends the given suggestions to the given error message.
+func withSuggestions(msg string, suggestions []string) error {
+	if len(suggestions) == 0 {
+		return errors.New(msg)
+	}
+	return fmt.Errorf("%s\n\nDid you mean?\n    %s", msg, strings.Join(suggestions, "\n    "))
+}
+
+func unknownCommandError(k string, cmds map[string]Command) error {
+	if k == "" {
+		return errNoRootCmd
+	}
+
+	names := make([]string, 0, len(cmds))
+	for name, c := range cmds {
+		if !c.(*command).isHidden {
+			names = append(names, name)
+		}
+	}
+
+	return withSuggestions("unknown command: "+k, suggest(k, names))
+}
+
+// checkFlags ensures that the flags from the given args are defined. Errors
+// name the undefined flags and suggest the closest visible options.
+func checkFlags(flags *flag.FlagSet, opts []option, args []string) error {
+	var errs []error
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			continue
+		}
+
+		name, _, hasValue := strings.Cut(strings.TrimPrefix(arg[1:], "-"), "=")
+		if f := flags.Lookup(name); f != nil {
+			if !hasValue && !isBoolFlag(f) {
+				i++
+			}
+			continue
+		}
+
+		if name == "h" || name == "help" {
+			continue
+		}
+
+		errs = append(errs, unknownOptionError(name, opts))
+	}
+
+	return errors.Join(errs...)
+}
+
+func unknownOptionError(name string, opts []option) error {
+	var names []string
+	for _, o := range opts {
+		if o.isHidden || o.isArg {
+			continue
+		}
+
+		names = append(names, o.name)
+		if o.short != "" {
+			names = append(names, o.short)
+		}
+	}
+
+	suggestions := suggest(name, names)
+	for i, s := range suggestions {
+		suggestions[i] = flagName(s)
+	}
+	return withSuggestions("unknown option: "+flagName(name), suggestions)
+}
+
+func flagName(name string) string {
+	if len([]rune(name)) == 1 {
+		return "-" + name
+	}
+	return "--" + name
+}
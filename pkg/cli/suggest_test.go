@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{a: "", b: "", expected: 0},
+		{a: "foo", b: "", expected: 3},
+		{a: "", b: "foo", expected: 3},
+		{a: "foo", b: "foo", expected: 0},
+		{a: "migrat", b: "migrate", expected: 1},
+		{a: "stpes", b: "steps", expected: 1},
+		{a: "kitten", b: "sitting", expected: 3},
+		{a: "héllo", b: "hello", expected: 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.a+" "+test.b, func(t *testing.T) {
+			require.Equal(t, test.expected, editDistance(test.a, test.b))
+		})
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	tests := []struct {
+		scenario   string
+		name       string
+		candidates []string
+		expected   []string
+	}{
+		{
+			scenario:   "close candidates sorted by distance",
+			name:       "migrat",
+			candidates: []string{"migrate", "migrate up", "version", "migra"},
+			expected:   []string{"migra", "migrate", "migrate up"},
+		},
+		{
+			scenario:   "candidates with the name as prefix",
+			name:       "ver",
+			candidates: []string{"verbose", "version", "value"},
+			expected:   []string{"verbose", "version"},
+		},
+		{
+			scenario:   "no close candidate",
+			name:       "deploy",
+			candidates: []string{"migrate", "version"},
+			expected:   []string{},
+		},
+		{
+			scenario:   "suggestions are limited",
+			name:       "ab",
+			candidates: []string{"a", "b", "abc", "abd", "abe"},
+			expected:   []string{"a", "abc", "abd"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			require.Equal(t, test.expected, suggest(test.name, test.candidates))
+		})
+	}
+}
+
+func TestCommandManagerSuggestions(t *testing.T) {
+	opts := struct {
+		Steps   int
+		Verbose bool   `short:"v"`
+		Secret  string `cli:"secret,hidden"`
+	}{}
+
+	m := commandManager{}
+	m.register("migrate", "up").Options(&opts)
+	m.register("migrate", "down")
+
+	tests := []struct {
+		scenario string
+		args     []string
+		expected string
+	}{
+		{
+			scenario: "unknown command",
+			args:     []string{"migrate", "upp"},
+			expected: "unknown command: migrate upp\n\nDid you mean?\n    migrate up",
+		},
+		{
+			scenario: "unknown command without suggestion",
+			args:     []string{"deploy"},
+			expected: "unknown command: deploy",
+		},
+		{
+			scenario: "unknown option",
+			args:     []string{"migrate", "up", "--stpes", "2"},
+			expected: "unknown option: --stpes\n\nDid you mean?\n    --steps",
+		},
+		{
+			scenario: "unknown options",
+			args:     []string{"migrate", "up", "--verbos", "-x", "--secrets"},
+			expected: "unknown option: --verbos\n\nDid you mean?\n    --verbose\nunknown option: -x\n\nDid you mean?\n    -v\nunknown option: --secrets",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			_, _, err := m.parse(test.args...)
+			require.Error(t, err)
+			require.Equal(t, test.expected, err.Error())
+			t.Log("error:", err)
+		})
+	}
+}
@@ -0,0 +1,26 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package cli
+
+import (
+	"errors"
+	"os"
+)
+
+// terminalWidth always reports that the given file is not a terminal on
+// platforms where terminals are not detected.
+func terminalWidth(f *os.File) (width int, ok bool) {
+	return 0, false
+}
+
+// isTerminal always reports that the given file is not a terminal on
+// platforms where terminals are not detected.
+func isTerminal(f *os.File) bool {
+	return false
+}
+
+// disableEcho always returns an error on platforms where terminals are not
+// detected.
+func disableEcho(f *os.File) (restore func(), err error) {
+	return nil, errors.New("disabling terminal echo is not supported")
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"encoding"
+	"flag"
+	"fmt"
+	"net/url"
+	"reflect"
+)
+
+var (
+	urlType = reflect.TypeOf(url.URL{})
+)
+
+// setText sets the option value with the given string when its type is parsed
+// from text: types that implement encoding.TextUnmarshaler or flag.Value, and
+// url.URL. It reports whether the option value type is parsed from text.
+func (o option) setText(s string) (bool, error) {
+	if !o.value.CanAddr() {
+		return false, nil
+	}
+
+	switch v := o.value.Addr().Interface().(type) {
+	case encoding.TextUnmarshaler:
+		return true, v.UnmarshalText([]byte(s))
+
+	case flag.Value:
+		return true, v.Set(s)
+
+	case *url.URL:
+		u, err := url.Parse(s)
+		if err != nil {
+			return true, err
+		}
+		*v = *u
+		return true, nil
+
+	default:
+		return false, nil
+	}
+}
+
+// textString returns the text representation of the option value when its type
+// implements encoding.TextMarshaler or fmt.Stringer. It reports whether the
+// option value has a text representation.
+func (o option) textString() (string, bool) {
+	if isNilPointer(o.value) {
+		return "", false
+	}
+
+	v := o.value.Interface()
+	if o.value.CanAddr() {
+		if _, ok := v.(fmt.Stringer); !ok {
+			v = o.value.Addr().Interface()
+		}
+	}
+
+	switch v := v.(type) {
+	case encoding.TextMarshaler:
+		b, err := v.MarshalText()
+		return string(b), err == nil
+
+	case fmt.Stringer:
+		return v.String(), true
+
+	default:
+		return "", false
+	}
+}
+
+// isText reports whether the option value type is parsed from text.
+func (o option) isText() bool {
+	t := o.value.Type()
+	if t == urlType {
+		return true
+	}
+
+	pt := reflect.PointerTo(t)
+	return pt.Implements(reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()) ||
+		pt.Implements(reflect.TypeOf((*flag.Value)(nil)).Elem())
+}
+
+func isNilPointer(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+
+	default:
+		return false
+	}
+}
@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/aukilabs/go-tooling/pkg/logs"
+	"github.com/stretchr/testify/require"
+)
+
+type textColor string
+
+func (c *textColor) UnmarshalText(text []byte) error {
+	switch s := string(text); s {
+	case "red", "blue":
+		*c = textColor(s)
+		return nil
+
+	default:
+		return errors.New("unknown color")
+	}
+}
+
+type flagList []string
+
+func (l *flagList) String() string {
+	return strings.Join(*l, "+")
+}
+
+func (l *flagList) Set(s string) error {
+	*l = strings.Split(s, "+")
+	return nil
+}
+
+type textOptions struct {
+	IP       net.IP
+	Endpoint url.URL
+	SlogLvl  slog.Level
+	LogLvl   logs.Level
+	Color    textColor
+	List     flagList
+}
+
+func TestTextOptions(t *testing.T) {
+	tests := []struct {
+		scenario string
+		args     []string
+		expected textOptions
+		err      bool
+	}{
+		{
+			scenario: "parse options from text",
+			args: []string{
+				"-ip", "10.0.0.1",
+				"-endpoint", "https://aukilabs.com/api",
+				"-slog-lvl", "warn",
+				"-log-lvl", "error",
+				"-color", "red",
+				"-list", "a+b",
+			},
+			expected: textOptions{
+				IP: net.ParseIP("10.0.0.1"),
+				Endpoint: url.URL{
+					Scheme: "https",
+					Host:   "aukilabs.com",
+					Path:   "/api",
+				},
+				SlogLvl: slog.LevelWarn,
+				LogLvl:  logs.ErrorLevel,
+				Color:   "red",
+				List:    flagList{"a", "b"},
+			},
+		},
+		{
+			scenario: "invalid text returns an error",
+			args:     []string{"-color", "green"},
+			err:      true,
+		},
+		{
+			scenario: "invalid log level returns an error",
+			args:     []string{"-log-lvl", "warn"},
+			err:      true,
+		},
+		{
+			scenario: "invalid url returns an error",
+			args:     []string{"-endpoint", "http://[::1"},
+			err:      true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			var opts textOptions
+
+			p := optionParser{flags: flag.NewFlagSet("test", flag.ContinueOnError)}
+			p.flags.SetOutput(writerNoop{})
+
+			options, err := p.parse(&opts)
+			require.NoError(t, err)
+			require.False(t, hasOption(options, "endpoint.host"))
+
+			err = p.flags.Parse(test.args)
+			if test.err {
+				require.Error(t, err)
+				t.Log("error:", err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, opts)
+		})
+	}
+}
+
+func TestTextOptionsString(t *testing.T) {
+	opts := textOptions{
+		IP:       net.ParseIP("10.0.0.1"),
+		Endpoint: url.URL{Scheme: "https", Host: "aukilabs.com"},
+		SlogLvl:  slog.LevelWarn,
+		LogLvl:   logs.ErrorLevel,
+		Color:    "red",
+		List:     flagList{"a", "b"},
+	}
+
+	v := reflect.ValueOf(&opts).Elem()
+	expected := []string{
+		"10.0.0.1",
+		"https://aukilabs.com",
+		"WARN",
+		"error",
+		"red",
+		"a+b",
+	}
+
+	for i, e := range expected {
+		require.Equal(t, e, option{value: v.Field(i)}.String())
+	}
+}
+
+func TestTextOptionsFromConfigFile(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(filename, []byte("ip: 10.0.0.1\nlog-lvl: 3\nslog-lvl: DEBUG\n"), 0666)
+	require.NoError(t, err)
+
+	var opts textOptions
+	p := optionParser{
+		flags:      flag.NewFlagSet("test", flag.ContinueOnError),
+		configFile: filename,
+	}
+
+	_, err = p.parse(&opts)
+	require.NoError(t, err)
+	require.Equal(t, net.ParseIP("10.0.0.1"), opts.IP)
+	require.Equal(t, logs.ErrorLevel, opts.LogLvl)
+	require.Equal(t, slog.LevelDebug, opts.SlogLvl)
+}
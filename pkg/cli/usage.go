@@ -4,141 +4,299 @@ import (
 	"bufio"
 	"fmt"
 	"io"
-	"os"
-	"path/filepath"
 	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 )
 
 const (
 	defaultColor = "\033[0m"
 	errorColor   = "\033[91m"
+	warningColor = "\033[93m"
 	successColor = "\033[92m"
 	accentColor  = "\033[94m"
 	focusColor   = "\033[1m"
 	subColor     = "\033[2m"
 )
 
-func commandUsage(w io.Writer, cmd *command, opts []option) func() {
+func commandUsage(w io.Writer, width int, programName string, cmd *command, opts []option, subcmds []*command) func() {
 	return func() {
 		// Usage:
 		fmt.Fprintf(w, "%sUsage:%s\n\n", accentColor, defaultColor)
 
 		indent(w, 4)
-		fmt.Fprint(w, focusColor, filepath.Base(os.Args[0]))
+		fmt.Fprint(w, focusColor, programName)
 		if cmd.name != "" {
 			fmt.Fprint(w, " ", cmd.name)
 		}
 		fmt.Fprint(w, defaultColor, accentColor, " [options]", defaultColor)
+		if args := argsUsage(opts); args != "" {
+			fmt.Fprint(w, " ", args)
+		}
+		if len(subcmds) != 0 {
+			fmt.Fprint(w, accentColor, " <command>", defaultColor)
+		}
 		fmt.Fprintln(w)
 		fmt.Fprintln(w)
 
+		// Aliases:
+		if len(cmd.aliases) != 0 {
+			fmt.Fprintf(w, "%sAliases:%s\n\n", accentColor, defaultColor)
+
+			parent := strings.Fields(cmd.name)
+			parent = parent[:len(parent)-1]
+			for _, a := range cmd.aliases {
+				indent(w, 4)
+				fmt.Fprintln(w, commandString(append([]string{programName}, append(parent, a)...)...))
+			}
+			fmt.Fprintln(w)
+		}
+
 		// Help:
 		if cmd.help != "" {
 			fmt.Fprintf(w, "%sDescription:%s\n\n", accentColor, defaultColor)
 			indent(w, 4)
-			writeText(w, cmd.help, 4, 80)
+			writeText(w, cmd.help, 4, width)
 			fmt.Fprintln(w)
 		}
 
-		// Options:
-		if len(opts) == 0 {
-			return
+		// Deprecation:
+		if cmd.deprecated != "" {
+			fmt.Fprintf(w, "%sDeprecated:%s\n\n", warningColor, defaultColor)
+			indent(w, 4)
+			writeText(w, cmd.deprecated, 4, width)
+			fmt.Fprintln(w)
 		}
 
-		fmt.Fprintf(w, "%sOptions:%s\n\n", accentColor, defaultColor)
-
 		optsInfo := optionsInfo(opts)
-		for _, o := range opts {
-			if o.isHidden {
-				continue
-			}
 
-			indent(w, 4)
-			fmt.Fprintf(w, "%s--%s%s", focusColor, o.name, defaultColor)
-			indent(w, optsInfo.nameLen-len(o.name)+1)
-
-			typeName := o.value.Type().String()
-			typeName = strings.TrimPrefix(typeName, "main.")
-			fmt.Fprintf(w, "%s%s%s", accentColor, typeName, defaultColor)
-			indent(w, optsInfo.typeLen-len(typeName)+4)
-
-			lastColIndent := 4 + 1 + optsInfo.nameLen + 2 + optsInfo.typeLen + 4
-			if o.help != "" {
-				writeText(w, o.help, lastColIndent, 80)
-				indent(w, lastColIndent)
-			}
+		// Arguments:
+		if args := commandArgs(opts); len(args) != 0 {
+			fmt.Fprintf(w, "%sArguments:%s\n\n", accentColor, defaultColor)
 
-			if o.envKey != "-" {
-				fmt.Fprintf(w, "%sEnv:%s     %s%s%s\n", subColor, defaultColor, accentColor, o.envKey, defaultColor)
-				indent(w, lastColIndent)
+			for _, a := range args {
+				indent(w, 4)
+				name := optsInfo.name(a)
+				fmt.Fprintf(w, "%s%s%s", focusColor, name, defaultColor)
+				indent(w, optsInfo.nameLen-len(name)+1)
+
+				typeName := optionTypeName(a)
+				fmt.Fprintf(w, "%s%s%s", accentColor, typeName, defaultColor)
+				indent(w, optsInfo.typeLen-len(typeName)+4)
+
+				lastColIndent := 4 + optsInfo.nameLen + 1 + optsInfo.typeLen + 4
+				writeText(w, a.help, lastColIndent, width)
+				fmt.Fprintln(w)
 			}
+		}
 
-			if !o.value.IsZero() {
-				switch o.value.Kind() {
-				case reflect.String,
-					reflect.Struct,
-					reflect.Map,
-					reflect.Array,
-					reflect.Slice:
-					fmt.Fprintf(w, "%sDefault:%s %q\n", subColor, defaultColor, o)
+		// Options:
+		if opts := documentedOptions(opts, false); len(opts) != 0 {
+			fmt.Fprintf(w, "%sOptions:%s\n\n", accentColor, defaultColor)
+			writeOptionRows(w, width, opts, optsInfo)
+		}
 
-				default:
-					fmt.Fprintf(w, "%sDefault:%s %s\n", subColor, defaultColor, o)
+		// Global options:
+		if opts := documentedOptions(opts, true); len(opts) != 0 {
+			fmt.Fprintf(w, "%sGlobal options:%s\n\n", accentColor, defaultColor)
+			writeOptionRows(w, width, opts, optsInfo)
+		}
+
+		// Commands:
+		if len(subcmds) != 0 {
+			fmt.Fprintf(w, "%sCommands:%s\n\n", accentColor, defaultColor)
 
-				}
+			rows := make([]commandIndexRow, 0, len(subcmds))
+			for _, sub := range subcmds {
+				rows = append(rows, newCommandIndexRow(sub, cmd.name, 0))
 			}
+			writeCommandIndexRows(w, width, rows, commandIndexNameLen(rows))
+		}
+	}
+}
 
-			fmt.Fprintln(w)
+// writeOptionRows writes the usage of the given options.
+func writeOptionRows(w io.Writer, width int, opts []option, optsInfo optionFormatInfo) {
+	for _, o := range opts {
+		indent(w, 4)
+		name := optsInfo.name(o)
+		fmt.Fprintf(w, "%s%s%s", focusColor, name, defaultColor)
+		indent(w, optsInfo.nameLen-len(name)+1)
+
+		typeName := optionTypeName(o)
+		fmt.Fprintf(w, "%s%s%s", accentColor, typeName, defaultColor)
+		indent(w, optsInfo.typeLen-len(typeName)+4)
+
+		lastColIndent := 4 + optsInfo.nameLen + 1 + optsInfo.typeLen + 4
+		if help := optionHelp(o); help != "" {
+			writeText(w, help, lastColIndent, width)
+			indent(w, lastColIndent)
+		}
+
+		if o.envKey != "-" {
+			fmt.Fprintf(w, "%sEnv:%s     %s%s%s\n", subColor, defaultColor, accentColor, o.envKeys(), defaultColor)
+			indent(w, lastColIndent)
+		}
+
+		if len(o.rules) != 0 {
+			fmt.Fprintf(w, "%sRules:%s   %s\n", subColor, defaultColor, optionRules(o))
+			indent(w, lastColIndent)
+		}
+
+		if def := optionDefault(o); def != "" {
+			fmt.Fprintf(w, "%sDefault:%s %s\n", subColor, defaultColor, def)
 		}
+
+		fmt.Fprintln(w)
 	}
 }
 
-func commandUsageIndex(w io.Writer, cmds map[string]Command) func() {
+func commandUsageIndex(w io.Writer, width int, programName string, cmds map[string]Command) func() {
 	return func() {
 		// Usage:
 		fmt.Fprintf(w, "%sUsage:%s\n\n", accentColor, defaultColor)
 
 		indent(w, 4)
-		fmt.Fprint(w, focusColor, filepath.Base(os.Args[0]))
+		fmt.Fprint(w, focusColor, programName)
 		fmt.Fprint(w, defaultColor, accentColor, " <command>", defaultColor)
 		fmt.Fprintln(w)
 		fmt.Fprintln(w)
 
-		// Commands:
-		fmt.Fprintf(w, "%sCommands:%s\n\n", accentColor, defaultColor)
+		// Commands, nested under their parent and grouped in sections:
+		sections := make(map[string][]commandIndexRow)
+		var groups []string
 
-		cmdlist := make([]*command, 0, len(cmds))
-		maxLenName := 0
-		for _, c := range cmds {
-			cmd := c.(*command)
+		var addRows func(group string, c *command, parent string, depth int)
+		addRows = func(group string, c *command, parent string, depth int) {
+			sections[group] = append(sections[group], newCommandIndexRow(c, parent, depth))
+			for _, sub := range subcommands(cmds, c.name) {
+				addRows(group, sub, c.name, depth+1)
+			}
+		}
 
-			if l := len(cmd.name); l > maxLenName {
-				maxLenName = l
+		for _, c := range subcommands(cmds, "") {
+			if _, ok := sections[c.group]; !ok && c.group != "" {
+				groups = append(groups, c.group)
 			}
+			addRows(c.group, c, "", 0)
+		}
+		sort.Strings(groups)
 
-			cmdlist = append(cmdlist, cmd)
+		var rows []commandIndexRow
+		for _, r := range sections {
+			rows = append(rows, r...)
 		}
+		nameLen := commandIndexNameLen(rows)
 
-		sort.Slice(cmdlist, func(i, j int) bool {
-			return strings.Compare(cmdlist[i].name, cmdlist[j].name) < 0
-		})
+		if rows := sections[""]; len(rows) != 0 {
+			fmt.Fprintf(w, "%sCommands:%s\n\n", accentColor, defaultColor)
+			writeCommandIndexRows(w, width, rows, nameLen)
+		}
 
-		for _, c := range cmdlist {
-			indent(w, 4)
-			fmt.Fprintf(w, "%s%s%s", focusColor, c.name, defaultColor)
-			indent(w, maxLenName-len(c.name)+4)
-			writeText(w, c.help, 4+maxLenName+4, 80)
-			fmt.Fprintln(w)
+		for _, g := range groups {
+			fmt.Fprintf(w, "%s%s:%s\n\n", accentColor, g, defaultColor)
+			writeCommandIndexRows(w, width, sections[g], nameLen)
+		}
+	}
+}
+
+// commandIndexRow is a command line displayed in a command index.
+type commandIndexRow struct {
+	name       string
+	help       string
+	deprecated string
+}
+
+// newCommandIndexRow returns the index row of the given command. The command
+// name is displayed relative to its parent, indented by its depth and followed
+// by its aliases.
+func newCommandIndexRow(c *command, parent string, depth int) commandIndexRow {
+	name := strings.TrimSpace(strings.TrimPrefix(c.name, parent))
+	if len(c.aliases) != 0 {
+		name += ", " + strings.Join(c.aliases, ", ")
+	}
+
+	return commandIndexRow{
+		name:       strings.Repeat(" ", 2*depth) + name,
+		help:       c.help,
+		deprecated: c.deprecated,
+	}
+}
+
+func commandIndexNameLen(rows []commandIndexRow) int {
+	nameLen := 0
+	for _, r := range rows {
+		if l := len(r.name); l > nameLen {
+			nameLen = l
 		}
 	}
+	return nameLen
+}
+
+func writeCommandIndexRows(w io.Writer, width int, rows []commandIndexRow, nameLen int) {
+	for _, r := range rows {
+		indent(w, 4)
+		fmt.Fprintf(w, "%s%s%s", focusColor, r.name, defaultColor)
+		indent(w, nameLen-len(r.name)+4)
+
+		help := r.help
+		if r.deprecated != "" {
+			help = strings.TrimSpace(help + " (deprecated: " + r.deprecated + ")")
+		}
+		writeText(w, help, 4+nameLen+4, width)
+		fmt.Fprintln(w)
+	}
+}
+
+// optionTypeName returns the type name of the given option as displayed in the
+// usage.
+func optionTypeName(o option) string {
+	return strings.TrimPrefix(o.value.Type().String(), "main.")
+}
+
+// optionDefault returns the formatted default value of the given option, or an
+// empty string when the option value is the zero value.
+func optionDefault(o option) string {
+	if o.value.IsZero() {
+		return ""
+	}
+
+	if o.isSecret {
+		return redactedValue
+	}
+
+	switch kind := o.value.Kind(); {
+	case o.isMultiValue():
+		return o.String()
+
+	case kind == reflect.String,
+		kind == reflect.Struct,
+		kind == reflect.Map,
+		kind == reflect.Array,
+		kind == reflect.Slice:
+		return strconv.Quote(o.String())
+
+	default:
+		return o.String()
+	}
 }
 
 func printError(w io.Writer, err error) {
 	fmt.Fprintf(w, "%sError:%s\n\n", errorColor, defaultColor)
-	indent(w, 4)
-	fmt.Fprintln(w, err)
+	for _, line := range strings.Split(err.Error(), "\n") {
+		indent(w, 4)
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintln(w)
+}
+
+func printWarning(w io.Writer, msg string) {
+	fmt.Fprintf(w, "%sWarning:%s\n\n", warningColor, defaultColor)
+	for _, line := range strings.Split(msg, "\n") {
+		indent(w, 4)
+		fmt.Fprintln(w, line)
+	}
 	fmt.Fprintln(w)
 }
 
@@ -190,20 +348,45 @@ func writeText(w io.Writer, text string, level, maxLen int) {
 }
 
 type optionFormatInfo struct {
-	nameLen int
-	typeLen int
+	nameLen  int
+	typeLen  int
+	hasShort bool
 }
 
 func optionsInfo(opts []option) optionFormatInfo {
 	var info optionFormatInfo
 	for _, o := range opts {
-		if l := len(o.name); l > info.nameLen {
-			info.nameLen = l
+		if o.short != "" {
+			info.hasShort = true
 		}
 
 		if l := len(o.value.Type().String()); l > info.typeLen {
 			info.typeLen = l
 		}
 	}
+
+	for _, o := range opts {
+		if l := len(info.name(o)); l > info.nameLen {
+			info.nameLen = l
+		}
+	}
 	return info
 }
+
+// name returns the option name as displayed in the usage. Option names are
+// prefixed by their short alias when options have one.
+func (info optionFormatInfo) name(o option) string {
+	switch {
+	case o.isArg:
+		return o.name
+
+	case o.short != "":
+		return "-" + o.short + ", --" + o.name
+
+	case info.hasShort:
+		return "    --" + o.name
+
+	default:
+		return "--" + o.name
+	}
+}
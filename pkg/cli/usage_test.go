@@ -4,7 +4,10 @@ import (
 	"bytes"
 	"errors"
 	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/stretchr/testify/require"
 )
 
 func TestCommandUsage(t *testing.T) {
@@ -29,6 +32,7 @@ func TestCommandUsage(t *testing.T) {
 				tempor incididunt ut labore et dolore magna aliqua.
 				`,
 			envKey: "FOO",
+			short:  "f",
 			value:  reflect.ValueOf(42),
 		},
 		{
@@ -42,6 +46,24 @@ func TestCommandUsage(t *testing.T) {
 			help:   "Alakazam option description.",
 			envKey: "BAR",
 			value:  reflect.ValueOf(0),
+			rules: []validationRule{
+				{name: "required"},
+				{name: "max", param: "10"},
+			},
+		},
+		{
+			name:   "hosts",
+			help:   "Hosts option description.",
+			envKey: "HOSTS",
+			value:  reflect.ValueOf([]string{"a", "b"}),
+		},
+		{
+			name:     "file",
+			help:     "The file to process.",
+			envKey:   "-",
+			value:    reflect.ValueOf(""),
+			isArg:    true,
+			argIndex: 0,
 		},
 		{
 			name:     "hidden",
@@ -53,7 +75,7 @@ func TestCommandUsage(t *testing.T) {
 	}
 
 	w := bytes.NewBufferString("\n")
-	usage := commandUsage(w, cmd, opts)
+	usage := commandUsage(w, defaultUsageWidth, "test", cmd, opts, nil)
 	usage()
 
 	t.Log(w.String())
@@ -75,14 +97,131 @@ func TestCommandUsageIndex(t *testing.T) {
 	m.register("foo", "buu").Help("A more simple help.")
 
 	w := bytes.NewBufferString("\n")
-	usage := commandUsageIndex(w, m.commands)
+	usage := commandUsageIndex(w, defaultUsageWidth, "test", m.commands)
 	usage()
 
 	t.Log(w.String())
 }
 
+func TestCommandUsageIndexTree(t *testing.T) {
+	m := commandManager{}
+
+	m.register().Help("The root command.")
+	m.register("version").Help("Prints the version.")
+	m.register("migrate").Help("Runs migrations.").Group("Database")
+	m.register("migrate", "up").Help("Applies migrations.").Aliases("u")
+	m.register("migrate", "down").Help("Reverts migrations.").Deprecated("use rollback instead")
+	m.register("rollback").Help("Reverts migrations.").Group("Database")
+	m.register("debug").Help("Debugs the program.").Hidden()
+	m.register("completion", "bash").Help("Generates the bash completion script.")
+
+	w := bytes.NewBuffer(nil)
+	commandUsageIndex(&noColorWriter{w: w}, defaultUsageWidth, "test", m.commands)()
+	require.Equal(t, ""+
+		"Usage:\n"+
+		"\n"+
+		"    test <command>\n"+
+		"\n"+
+		"Commands:\n"+
+		"\n"+
+		"    completion bash    Generates the bash completion script.\n"+
+		"\n"+
+		"    version            Prints the version.\n"+
+		"\n"+
+		"Database:\n"+
+		"\n"+
+		"    migrate            Runs migrations.\n"+
+		"\n"+
+		"      down             Reverts migrations. (deprecated: use rollback instead)\n"+
+		"\n"+
+		"      up, u            Applies migrations.\n"+
+		"\n"+
+		"    rollback           Reverts migrations.\n"+
+		"\n",
+		w.String(),
+	)
+}
+
+func TestCommandUsageRootAliases(t *testing.T) {
+	w := bytes.NewBuffer(nil)
+	code := 0
+
+	app := New("test")
+	app.Args = []string{"-h"}
+	app.Stderr = w
+	app.Exit = func(c int) { code = c }
+	app.Register().Help("A root command.").Aliases("r")
+
+	app.Load()
+	require.Equal(t, -1, code)
+	require.Contains(t, w.String(), "A root command.")
+	require.NotContains(t, w.String(), "Aliases:")
+}
+
+func TestCommandUsageSubcommands(t *testing.T) {
+	m := commandManager{}
+	m.register("migrate").Help("Runs migrations.").Aliases("m").Deprecated("use db migrate instead")
+	m.register("migrate", "up").Help("Applies migrations.")
+	m.register("migrate", "up", "all").Help("Applies all migrations.")
+
+	w := bytes.NewBuffer(nil)
+	cmd := m.commands["migrate"].(*command)
+	commandUsage(&noColorWriter{w: w}, defaultUsageWidth, "test", cmd, nil, subcommands(m.commands, "migrate"))()
+	require.Equal(t, ""+
+		"Usage:\n"+
+		"\n"+
+		"    test migrate [options] <command>\n"+
+		"\n"+
+		"Aliases:\n"+
+		"\n"+
+		"    test m\n"+
+		"\n"+
+		"Description:\n"+
+		"\n"+
+		"    Runs migrations.\n"+
+		"\n"+
+		"Deprecated:\n"+
+		"\n"+
+		"    use db migrate instead\n"+
+		"\n"+
+		"Commands:\n"+
+		"\n"+
+		"    up    Applies migrations.\n"+
+		"\n",
+		w.String(),
+	)
+}
+
 func TestPrintError(t *testing.T) {
 	w := bytes.NewBufferString("\n")
 	printError(w, errors.New("an error for testing printing"))
 	t.Log(w.String())
 }
+
+func TestCommandUsageGlobalOptions(t *testing.T) {
+	global := struct {
+		Verbose bool `help:"Enables verbose logs."`
+	}{}
+
+	up := struct {
+		Steps int `help:"The number of migrations to apply."`
+	}{}
+
+	m := commandManager{}
+	m.register().PersistentOptions(&global)
+	m.register("migrate", "up").Options(&up)
+
+	p := optionParser{persistent: persistentOptions(m.commands, "migrate up")}
+	err := p.collect(&up)
+	require.NoError(t, err)
+
+	w := bytes.NewBuffer(nil)
+	cmd := m.commands["migrate up"].(*command)
+	commandUsage(&noColorWriter{w: w}, defaultUsageWidth, "test", cmd, p.options, nil)()
+
+	usage := w.String()
+	require.Contains(t, usage, "Global options:\n\n    --verbose ")
+	require.Less(t, strings.Index(usage, "--steps"), strings.Index(usage, "Global options:"))
+	require.Less(t, strings.Index(usage, "--config"), strings.Index(usage, "Global options:"))
+	t.Log(usage)
+}
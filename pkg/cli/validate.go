@@ -0,0 +1,253 @@
+package cli
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type validationRule struct {
+	name    string
+	param   string
+	values  []string
+	pattern *regexp.Regexp
+}
+
+func (r validationRule) String() string {
+	if r.param == "" {
+		return r.name
+	}
+	return r.name + "=" + r.param
+}
+
+// parseValidateTag parses the rules of a validate tag. Rules are separated by
+// commas. Since patterns can contain commas, a pattern rule must be the last
+// rule of the tag.
+func parseValidateTag(tag string) ([]validationRule, error) {
+	var rules []validationRule
+
+	for tag != "" {
+		var rule string
+		if strings.HasPrefix(tag, "pattern=") {
+			rule, tag = tag, ""
+		} else {
+			rule, tag, _ = strings.Cut(tag, ",")
+		}
+
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		name, param, _ := strings.Cut(rule, "=")
+		r := validationRule{name: name, param: param}
+
+		switch name {
+		case "required":
+			if param != "" {
+				return nil, fmt.Errorf("validation rule %q does not take a parameter", name)
+			}
+
+		case "min", "max":
+			if param == "" {
+				return nil, fmt.Errorf("validation rule %q requires a parameter", name)
+			}
+
+		case "oneof":
+			if param == "" {
+				return nil, fmt.Errorf("validation rule %q requires a parameter", name)
+			}
+			r.values = strings.Split(param, "|")
+
+		case "pattern":
+			pattern, err := regexp.Compile(param)
+			if err != nil {
+				return nil, fmt.Errorf("validation rule %q is invalid: %w", name, err)
+			}
+			r.pattern = pattern
+
+		default:
+			return nil, fmt.Errorf("unknown validation rule: %q", name)
+		}
+
+		rules = append(rules, r)
+	}
+
+	return rules, nil
+}
+
+// checkRuleParams ensures that the parameters of the option min and max rules
+// can be compared with the option value, so that invalid tags are reported
+// when options are parsed instead of when they are set.
+func checkRuleParams(o option) error {
+	zero := option{value: reflect.New(o.value.Type()).Elem()}
+
+	for _, r := range o.rules {
+		if r.name != "min" && r.name != "max" {
+			continue
+		}
+
+		if _, err := compareOption(zero, r.param); err != nil {
+			return fmt.Errorf("validation rule %q %w", r, err)
+		}
+	}
+	return nil
+}
+
+// validateOptions checks the validation rules of the given options and returns
+// an error that reports every violation. Rules other than required are not
+// checked when the option keeps its zero default value.
+func validateOptions(opts []option) error {
+	var errs []error
+
+	for _, o := range opts {
+		// Options of optional structs that are not configured are not
+		// validated.
+		if !o.isParentConfigured() {
+			continue
+		}
+
+		for _, r := range o.rules {
+			if r.name != "required" && o.isZero() && o.sourceString() == sourceDefault {
+				continue
+			}
+
+			if err := r.validate(o); err != nil {
+				name := "--" + o.name
+				if o.isArg {
+					name = o.name
+				}
+				errs = append(errs, fmt.Errorf("%s %w", name, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (r validationRule) validate(o option) error {
+	switch r.name {
+	case "required":
+		if o.isZero() {
+			return errors.New("is required")
+		}
+
+	case "min":
+		res, err := compareOption(o, r.param)
+		if err != nil {
+			return err
+		}
+		if res < 0 {
+			return fmt.Errorf("must be at least %s", r.param)
+		}
+
+	case "max":
+		res, err := compareOption(o, r.param)
+		if err != nil {
+			return err
+		}
+		if res > 0 {
+			return fmt.Errorf("must be at most %s", r.param)
+		}
+
+	case "oneof":
+		for _, s := range optionStrings(o) {
+			if !containsString(r.values, s) {
+				return fmt.Errorf("must be one of %s: %q is not allowed", strings.Join(r.values, ", "), o.redact(s))
+			}
+		}
+
+	case "pattern":
+		for _, s := range optionStrings(o) {
+			if !r.pattern.MatchString(s) {
+				return fmt.Errorf("must match %q: %q does not match", r.param, o.redact(s))
+			}
+		}
+	}
+
+	return nil
+}
+
+// compareOption compares the option value with the given parameter. Numbers are
+// compared by value, durations can be compared with a duration literal and
+// strings, slices and maps are compared by length.
+func compareOption(o option, param string) (int, error) {
+	v := o.value
+
+	if _, ok := v.Interface().(time.Duration); ok {
+		if d, err := time.ParseDuration(param); err == nil {
+			return cmp.Compare(v.Int(), int64(d)), nil
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(param, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("has an invalid rule parameter: %w", err)
+		}
+		return cmp.Compare(v.Int(), n), nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("has an invalid rule parameter: %w", err)
+		}
+		return cmp.Compare(v.Uint(), n), nil
+
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return 0, fmt.Errorf("has an invalid rule parameter: %w", err)
+		}
+		return cmp.Compare(v.Float(), n), nil
+
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		n, err := strconv.Atoi(param)
+		if err != nil {
+			return 0, fmt.Errorf("has an invalid rule parameter: %w", err)
+		}
+		return cmp.Compare(v.Len(), n), nil
+
+	default:
+		return 0, fmt.Errorf("cannot be compared with %s", param)
+	}
+}
+
+// optionStrings returns the string representations of the option value. Slice
+// and array values return the representation of each of their elements.
+func optionStrings(o option) []string {
+	switch o.value.Kind() {
+	case reflect.Slice, reflect.Array:
+		s := make([]string, 0, o.value.Len())
+		for i := 0; i < o.value.Len(); i++ {
+			s = append(s, option{value: o.value.Index(i)}.String())
+		}
+		return s
+
+	default:
+		return []string{o.String()}
+	}
+}
+
+func optionRules(o option) string {
+	rules := make([]string, 0, len(o.rules))
+	for _, r := range o.rules {
+		rules = append(rules, r.String())
+	}
+	return strings.Join(rules, ", ")
+}
+
+func optionEnumValues(o option) []string {
+	for _, r := range o.rules {
+		if r.name == "oneof" {
+			return r.values
+		}
+	}
+	return nil
+}
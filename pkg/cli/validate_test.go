@@ -0,0 +1,230 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseValidateTag(t *testing.T) {
+	tests := []struct {
+		scenario string
+		tag      string
+		rules    []string
+		err      bool
+	}{
+		{
+			scenario: "empty tag",
+		},
+		{
+			scenario: "multiple rules",
+			tag:      "required, min=1,max=10",
+			rules:    []string{"required", "min=1", "max=10"},
+		},
+		{
+			scenario: "oneof rule",
+			tag:      "oneof=a|b|c",
+			rules:    []string{"oneof=a|b|c"},
+		},
+		{
+			scenario: "pattern with commas",
+			tag:      "required,pattern=^a{1,3}$",
+			rules:    []string{"required", "pattern=^a{1,3}$"},
+		},
+		{
+			scenario: "unknown rule returns an error",
+			tag:      "foo=bar",
+			err:      true,
+		},
+		{
+			scenario: "required with parameter returns an error",
+			tag:      "required=true",
+			err:      true,
+		},
+		{
+			scenario: "min without parameter returns an error",
+			tag:      "min",
+			err:      true,
+		},
+		{
+			scenario: "oneof without parameter returns an error",
+			tag:      "oneof=",
+			err:      true,
+		},
+		{
+			scenario: "invalid pattern returns an error",
+			tag:      "pattern=[a-",
+			err:      true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			rules, err := parseValidateTag(test.tag)
+			if test.err {
+				require.Error(t, err)
+				t.Log("error:", err)
+				return
+			}
+			require.NoError(t, err)
+
+			var ruleStrings []string
+			for _, r := range rules {
+				ruleStrings = append(ruleStrings, r.String())
+			}
+			require.Equal(t, test.rules, ruleStrings)
+		})
+	}
+}
+
+func TestValidateOptions(t *testing.T) {
+	type options struct {
+		Endpoint string        `validate:"required,pattern=^https?://"`
+		Port     int           `validate:"min=1,max=65535"`
+		Ratio    float64       `validate:"max=1"`
+		Level    string        `validate:"oneof=debug|info|warning|error"`
+		Timeout  time.Duration `validate:"min=1s"`
+		Hosts    []string      `validate:"min=1,oneof=a|b"`
+		Count    uint          `validate:"max=3"`
+	}
+
+	tests := []struct {
+		scenario string
+		args     []string
+		errs     []string
+	}{
+		{
+			scenario: "valid options",
+			args: []string{
+				"-endpoint", "https://aukilabs.com",
+				"-port", "8080",
+				"-ratio", "0.5",
+				"-level", "info",
+				"-timeout", "2s",
+				"-hosts", `["a","b"]`,
+				"-count", "3",
+			},
+		},
+		{
+			scenario: "zero default values are only checked for required",
+			args:     []string{},
+			errs: []string{
+				"--endpoint is required",
+			},
+		},
+		{
+			scenario: "zero values that are set are checked",
+			args: []string{
+				"-endpoint", "https://aukilabs.com",
+				"-port", "0",
+				"-timeout", "0s",
+				"-hosts", "[]",
+			},
+			errs: []string{
+				"--port must be at least 1",
+				"--timeout must be at least 1s",
+				"--hosts must be at least 1",
+			},
+		},
+		{
+			scenario: "every violation is reported",
+			args: []string{
+				"-endpoint", "ftp://aukilabs.com",
+				"-port", "70000",
+				"-ratio", "1.5",
+				"-level", "trace",
+				"-timeout", "10ms",
+				"-hosts", `["c"]`,
+				"-count", "4",
+			},
+			errs: []string{
+				"--endpoint must match",
+				"--port must be at most 65535",
+				"--ratio must be at most 1",
+				"--level must be one of debug, info, warning, error",
+				"--timeout must be at least 1s",
+				"--hosts must be one of a, b",
+				"--count must be at most 3",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			var opts options
+
+			m := commandManager{}
+			m.register().Options(&opts)
+
+			_, _, err := m.parse(test.args...)
+			if len(test.errs) == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			require.Error(t, err)
+			for _, e := range test.errs {
+				require.Contains(t, err.Error(), e)
+			}
+			t.Log("error:", err)
+		})
+	}
+}
+
+func TestValidateOptionsInvalidTag(t *testing.T) {
+	opts := struct {
+		Port int `validate:"between=1|2"`
+	}{}
+
+	m := commandManager{}
+	m.register().Options(&opts)
+
+	_, _, err := m.parse()
+	require.Error(t, err)
+	t.Log("error:", err)
+}
+
+func TestValidateOptionsInvalidParameter(t *testing.T) {
+	tests := []struct {
+		scenario string
+		opts     interface{}
+	}{
+		{
+			scenario: "invalid number parameter",
+			opts: &struct {
+				Port int `validate:"min=one"`
+			}{},
+		},
+		{
+			scenario: "invalid length parameter",
+			opts: &struct {
+				Name string `validate:"max=1.5"`
+			}{},
+		},
+		{
+			scenario: "invalid duration parameter",
+			opts: &struct {
+				Timeout time.Duration `validate:"min=abc"`
+			}{},
+		},
+		{
+			scenario: "parameter on a type that cannot be compared",
+			opts: &struct {
+				Verbose bool `validate:"max=1"`
+			}{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			m := commandManager{}
+			m.register().Options(test.opts)
+
+			_, _, err := m.parse()
+			require.Error(t, err)
+			require.ErrorContains(t, err, "parsing options failed")
+			t.Log("error:", err)
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// Build variables that override the values read from the Go build information
+// when set with ldflags. eg.
+//
+//	go build -ldflags "-X github.com/aukilabs/go-tooling/pkg/cli.Version=v1.2.0"
+var (
+	Version   string
+	Revision  string
+	BuildTime string
+)
+
+const (
+	versionFlagName = "version"
+)
+
+var (
+	versionFormats = []string{Text, JSON}
+)
+
+// BuildInfo describes the build of the program.
+type BuildInfo struct {
+	Version   string `json:"version"`
+	Revision  string `json:"revision,omitempty"`
+	Dirty     bool   `json:"dirty,omitempty"`
+	BuildTime string `json:"build_time,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// ReadBuildInfo returns the build information embedded in the program binary
+// by the Go toolchain. The Version and Revision variables override the embedded
+// values when they are set. The build time is only set from the BuildTime
+// variable since the Go toolchain does not embed it.
+func ReadBuildInfo() BuildInfo {
+	info := BuildInfo{GoVersion: runtime.Version()}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		info = newBuildInfo(bi)
+	}
+
+	if Version != "" {
+		info.Version = Version
+	}
+	if Revision != "" {
+		info.Revision = Revision
+	}
+	if BuildTime != "" {
+		info.BuildTime = BuildTime
+	}
+	return info
+}
+
+func newBuildInfo(bi *debug.BuildInfo) BuildInfo {
+	info := BuildInfo{
+		Version:   bi.Main.Version,
+		GoVersion: bi.GoVersion,
+	}
+
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			info.Revision = s.Value
+
+		case "vcs.modified":
+			info.Dirty = s.Value == "true"
+		}
+	}
+	return info
+}
+
+// WriteVersion writes the program build information in the given format.
+// Supported formats are text and json.
+func WriteVersion(w io.Writer, format string) error {
+	return writeVersion(w, format, ReadBuildInfo())
+}
+
+// RegisterVersion registers the version command and the --version flag in the
+// default app. See App.RegisterVersion.
+func RegisterVersion() {
+	defaultApp.RegisterVersion()
+}
+
+// RegisterVersion registers the "version" command and the --version flag that
+// print the program build information on the app standard output when loaded.
+// The --version flag is not added to commands that already define an option
+// named version.
+func (a *App) RegisterVersion() {
+	opts := versionOptions{
+		Format: Text,
+	}
+
+	c := a.Register("version").
+		Help("Prints the program version.").
+		Options(&opts).(*command)
+	c.builtin = func() error {
+		return WriteVersion(a.stdout(), opts.Format)
+	}
+
+	a.manager.hasVersionFlag = true
+}
+
+type versionOptions struct {
+	Format string `env:"-" validate:"oneof=text|json" help:"The output format."`
+}
+
+func writeVersion(w io.Writer, format string, info BuildInfo) error {
+	switch format {
+	case Text:
+		fmt.Fprintf(w, "Version:    %s\n", info.Version)
+		if info.Revision != "" {
+			revision := info.Revision
+			if info.Dirty {
+				revision += " (dirty)"
+			}
+			fmt.Fprintf(w, "Revision:   %s\n", revision)
+		}
+		if info.BuildTime != "" {
+			fmt.Fprintf(w, "Build time: %s\n", info.BuildTime)
+		}
+		fmt.Fprintf(w, "Go version: %s\n", info.GoVersion)
+		return nil
+
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(info)
+
+	default:
+		return fmt.Errorf("unsupported version format: %q", format)
+	}
+}
+
+// lookupVersionFlag returns the format given to the --version flag in the
+// given args.
+func lookupVersionFlag(args []string) (format string, ok bool) {
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		}
+
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(arg[1:], "-"), "=")
+		if name != versionFlagName {
+			continue
+		}
+
+		if !hasValue {
+			value = "true"
+		}
+		f := formatFlag{format: &format, formats: versionFormats}
+		return format, f.Set(value) == nil && format != ""
+	}
+	return "", false
+}
@@ -0,0 +1,231 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewBuildInfo(t *testing.T) {
+	info := newBuildInfo(&debug.BuildInfo{
+		GoVersion: "go1.23.0",
+		Main:      debug.Module{Version: "v1.2.0"},
+		Settings: []debug.BuildSetting{
+			{Key: "vcs", Value: "git"},
+			{Key: "vcs.revision", Value: "8f3c2a1"},
+			{Key: "vcs.time", Value: "2024-05-01T10:00:00Z"},
+			{Key: "vcs.modified", Value: "true"},
+		},
+	})
+
+	require.Equal(t, BuildInfo{
+		Version:   "v1.2.0",
+		Revision:  "8f3c2a1",
+		Dirty:     true,
+		GoVersion: "go1.23.0",
+	}, info)
+}
+
+func TestReadBuildInfo(t *testing.T) {
+	defer func(version, revision, buildTime string) {
+		Version = version
+		Revision = revision
+		BuildTime = buildTime
+	}(Version, Revision, BuildTime)
+
+	info := ReadBuildInfo()
+	require.NotEmpty(t, info.GoVersion)
+
+	Version = "v2.0.0"
+	Revision = "abc"
+	BuildTime = "2024-06-01T00:00:00Z"
+
+	info = ReadBuildInfo()
+	require.Equal(t, "v2.0.0", info.Version)
+	require.Equal(t, "abc", info.Revision)
+	require.Equal(t, "2024-06-01T00:00:00Z", info.BuildTime)
+}
+
+func TestWriteVersion(t *testing.T) {
+	info := BuildInfo{
+		Version:   "v1.2.0",
+		Revision:  "8f3c2a1",
+		Dirty:     true,
+		BuildTime: "2024-05-01T10:00:00Z",
+		GoVersion: "go1.23.0",
+	}
+
+	t.Run("text", func(t *testing.T) {
+		w := bytes.NewBuffer(nil)
+		err := writeVersion(w, Text, info)
+		require.NoError(t, err)
+		require.Equal(t, ""+
+			"Version:    v1.2.0\n"+
+			"Revision:   8f3c2a1 (dirty)\n"+
+			"Build time: 2024-05-01T10:00:00Z\n"+
+			"Go version: go1.23.0\n",
+			w.String(),
+		)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		w := bytes.NewBuffer(nil)
+		err := writeVersion(w, JSON, info)
+		require.NoError(t, err)
+
+		var decoded BuildInfo
+		err = json.Unmarshal(w.Bytes(), &decoded)
+		require.NoError(t, err)
+		require.Equal(t, info, decoded)
+		t.Log(w.String())
+	})
+
+	t.Run("unsupported format returns an error", func(t *testing.T) {
+		err := writeVersion(bytes.NewBuffer(nil), "xml", info)
+		require.Error(t, err)
+		t.Log("error:", err)
+	})
+}
+
+func TestLookupVersionFlag(t *testing.T) {
+	tests := []struct {
+		scenario       string
+		args           []string
+		expectedFormat string
+		expectedOK     bool
+	}{
+		{
+			scenario: "no flag",
+			args:     []string{"--foo"},
+		},
+		{
+			scenario:       "bool flag",
+			args:           []string{"--version"},
+			expectedFormat: Text,
+			expectedOK:     true,
+		},
+		{
+			scenario:       "json format",
+			args:           []string{"-version=json"},
+			expectedFormat: JSON,
+			expectedOK:     true,
+		},
+		{
+			scenario: "false",
+			args:     []string{"--version=false"},
+		},
+		{
+			scenario: "after double dash",
+			args:     []string{"--", "--version"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			format, ok := lookupVersionFlag(test.args)
+			require.Equal(t, test.expectedFormat, format)
+			require.Equal(t, test.expectedOK, ok)
+		})
+	}
+}
+
+func TestRegisterVersion(t *testing.T) {
+	tests := []struct {
+		scenario string
+		args     []string
+		root     bool
+		version  bool
+		code     int
+	}{
+		{
+			scenario: "version command",
+			args:     []string{"version"},
+			version:  true,
+		},
+		{
+			scenario: "version command with json format",
+			args:     []string{"version", "--format", "json"},
+			version:  true,
+		},
+		{
+			scenario: "version command with required persistent options",
+			args:     []string{"version"},
+			root:     true,
+			version:  true,
+		},
+		{
+			scenario: "version flag without root command",
+			args:     []string{"--version"},
+			version:  true,
+		},
+		{
+			scenario: "version flag on root command",
+			args:     []string{"--version=json"},
+			root:     true,
+			version:  true,
+		},
+		{
+			scenario: "version flag skips validation",
+			args:     []string{"deploy", "--version"},
+			version:  true,
+		},
+		{
+			scenario: "missing required option without version flag",
+			args:     []string{"deploy"},
+			code:     -1,
+		},
+		{
+			scenario: "version flag defined by the command",
+			args:     []string{"release", "--version", "v2"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			stdout := bytes.NewBuffer(nil)
+			code := 42
+
+			app := New("test")
+			app.Args = test.args
+			app.Stdout = stdout
+			app.Stderr = writerNoop{}
+			app.LookupEnv = func(string) (string, bool) { return "", false }
+			app.Exit = func(c int) { code = c }
+			app.RegisterVersion()
+
+			if test.root {
+				app.Register().PersistentOptions(&struct {
+					Region string `validate:"required"`
+				}{})
+			}
+
+			app.Register("deploy").Options(&struct {
+				Target string `validate:"required"`
+			}{})
+
+			releaseOpts := struct {
+				Version string
+			}{}
+			app.Register("release").Options(&releaseOpts)
+
+			app.Load()
+			if test.version {
+				require.Equal(t, 0, code)
+				require.NotEmpty(t, stdout.String())
+				t.Log(stdout.String())
+				return
+			}
+
+			require.Empty(t, stdout.String())
+			if test.code != 0 {
+				require.Equal(t, test.code, code)
+				return
+			}
+			require.Equal(t, 42, code)
+			require.Equal(t, "v2", releaseOpts.Version)
+		})
+	}
+}
@@ -81,6 +81,44 @@ func (l Level) String() string {
 	}
 }
 
+// Parses a level from a text. The text is either a level name or an integer.
+// It implements the encoding.TextUnmarshaler interface.
+func (l *Level) UnmarshalText(text []byte) error {
+	v := string(text)
+
+	switch v {
+	case "debug", "info", "warning", "error":
+
+	default:
+		if _, err := strconv.Atoi(v); err != nil {
+			return fmt.Errorf("unknown log level: %q", v)
+		}
+	}
+
+	*l = ParseLevel(v)
+	return nil
+}
+
+// Parses a level from a JSON number or a JSON string containing a level name
+// or an integer. It implements the json.Unmarshaler interface.
+func (l *Level) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var v int
+	if err := json.Unmarshal(data, &v); err == nil {
+		*l = Level(v)
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("unknown log level: %s", data)
+	}
+	return l.UnmarshalText([]byte(s))
+}
+
 // Sets what log levels are logged. Levels under the given level are ignored.
 func SetLevel(v Level) {
 	loggerMutex.Lock()
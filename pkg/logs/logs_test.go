@@ -79,6 +79,77 @@ func TestLevelString(t *testing.T) {
 	}
 }
 
+func TestLevelUnmarshalText(t *testing.T) {
+	var l Level
+	err := l.UnmarshalText([]byte("warning"))
+	require.NoError(t, err)
+	require.Equal(t, WarningLevel, l)
+
+	err = l.UnmarshalText([]byte("42"))
+	require.NoError(t, err)
+	require.Equal(t, Level(42), l)
+
+	err = l.UnmarshalText([]byte("warn"))
+	require.Error(t, err)
+	require.Equal(t, Level(42), l)
+	t.Log("error:", err)
+}
+
+func TestLevelUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		scenario string
+		json     string
+		expected Level
+		err      bool
+	}{
+		{
+			scenario: "number",
+			json:     `{"Level":2}`,
+			expected: WarningLevel,
+		},
+		{
+			scenario: "name",
+			json:     `{"Level":"error"}`,
+			expected: ErrorLevel,
+		},
+		{
+			scenario: "integer string",
+			json:     `{"Level":"42"}`,
+			expected: Level(42),
+		},
+		{
+			scenario: "null",
+			json:     `{"Level":null}`,
+			expected: InfoLevel,
+		},
+		{
+			scenario: "unknown name",
+			json:     `{"Level":"warn"}`,
+			err:      true,
+		},
+		{
+			scenario: "invalid type",
+			json:     `{"Level":true}`,
+			err:      true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.scenario, func(t *testing.T) {
+			v := struct{ Level Level }{Level: InfoLevel}
+
+			err := json.Unmarshal([]byte(test.json), &v)
+			if test.err {
+				require.Error(t, err)
+				t.Log("error:", err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expected, v.Level)
+		})
+	}
+}
+
 func TestSetLevel(t *testing.T) {
 	SetLevel(WarningLevel)
 